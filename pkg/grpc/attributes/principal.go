@@ -0,0 +1,45 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package attributes
+
+import (
+	"context"
+)
+
+// Principal 描述一次请求经过认证后的调用方身份
+// 由服务端认证拦截器写入上下文，业务代码和后续拦截器可以通过 PrincipalFromContext 读取
+type Principal struct {
+	Subject  string            // 主体标识，例如密钥所有者或服务名
+	KeyLabel string            // 认证所使用的密钥标签，便于轮换时定位具体密钥
+	AuthType string            // 认证方式，例如 apikey、hmac
+	Extra    map[string]string // 其他附加属性
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal 将调用方身份写入上下文
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext 从上下文中读取调用方身份
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package attributes
+
+import (
+	"context"
+)
+
+// PriorityMetadataKey 请求优先级所在的 metadata 键
+const PriorityMetadataKey = "x-request-priority"
+
+// Priority 请求优先级，数值越大越重要，服务端过载时优先拒绝低优先级的请求
+type Priority int
+
+const (
+	PrioritySheddable Priority = iota // 可丢弃，例如预取、统计上报
+	PriorityDefault                   // 默认
+	PriorityCritical                  // 关键，例如登录、支付
+)
+
+// String 返回优先级在 metadata 中的取值
+func (p Priority) String() string {
+	switch p {
+	case PrioritySheddable:
+		return "sheddable"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return "default"
+	}
+}
+
+// ParsePriority 解析 metadata 中的优先级
+func ParsePriority(s string) (Priority, bool) {
+	switch s {
+	case "sheddable":
+		return PrioritySheddable, true
+	case "default":
+		return PriorityDefault, true
+	case "critical":
+		return PriorityCritical, true
+	default:
+		return PriorityDefault, false
+	}
+}
+
+type priorityKey struct{}
+
+// ContextWithPriority 设置本次调用的优先级，客户端优先级拦截器会将其写入 metadata
+func ContextWithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext 读取调用方设置的优先级
+func PriorityFromContext(ctx context.Context) (Priority, bool) {
+	p, ok := ctx.Value(priorityKey{}).(Priority)
+	return p, ok
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package attributes
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// 请求签名使用的 metadata 键，客户端和服务端共用
+const (
+	SignatureMetadataKey          = "x-signature"
+	SignatureTimestampMetadataKey = "x-signature-timestamp" // Unix 毫秒时间戳
+	SignatureNonceMetadataKey     = "x-signature-nonce"
+)
+
+// ErrSignatureUnknownFields 签名校验失败且服务端解码后的请求包含未知字段，
+// 说明客户端使用了更新的 proto 定义（例如滚动发布期间新增了字段），服务端无法还原客户端签名的字节。
+// 服务端以 FailedPrecondition 返回该错误，与伪造签名的 Unauthenticated 区分开
+var ErrSignatureUnknownFields = errors.New("signature: request contains fields unknown to the server, client and server proto definitions differ")
+
+// SignaturePayload 将请求消息序列化为参与签名的字节
+// 使用确定性序列化，保证客户端和服务端对同一消息得到相同的字节；流式握手时 msg 为 nil
+// 确定性序列化只在双方使用相同 proto 定义时才一致，包含未知字段的消息在不同版本间可能得到不同的字节
+func SignaturePayload(msg interface{}) ([]byte, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("signature: message %T is not a proto.Message", msg)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
+
+// ComputeSignature 计算请求签名
+// 签名内容为 method、timestamp、nonce 以及请求体摘要，以换行分隔后做 HMAC-SHA256
+func ComputeSignature(secret []byte, method, timestamp, nonce string, payload []byte) string {
+	digest := sha256.Sum256(payload)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(method))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(hex.EncodeToString(digest[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HasUnknownFields 消息或其嵌套的消息中是否包含未知字段
+func HasUnknownFields(msg proto.Message) bool {
+	if msg == nil {
+		return false
+	}
+	return hasUnknownFields(msg.ProtoReflect())
+}
+
+func hasUnknownFields(m protoreflect.Message) bool {
+	if len(m.GetUnknown()) > 0 {
+		return true
+	}
+	found := false
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len() && !found; i++ {
+				found = hasUnknownFields(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				found = hasUnknownFields(mv.Message())
+				return !found
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			found = hasUnknownFields(v.Message())
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,417 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 关闭，请求正常通过
+	CircuitOpen                         // 打开，请求直接失败
+	CircuitHalfOpen                     // 半开，只允许少量探测请求通过
+)
+
+// String 返回状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerConfig 熔断器配置
+type CircuitBreakerConfig struct {
+	Window              time.Duration // 统计失败率的滚动窗口
+	Buckets             int           // 滚动窗口划分的桶数
+	MinRequests         int           // 窗口内请求数达到此值才计算失败率
+	FailureRatio        float64       // 窗口内失败率达到此值时打开熔断器，为0时不启用
+	ConsecutiveFailures int           // 连续失败达到此值时打开熔断器，为0时不启用
+	OpenTimeout         time.Duration // 打开状态持续的时间，之后进入半开状态
+	HalfOpenMaxRequests int           // 半开状态允许同时通过的探测请求数，同样数量的探测成功后关闭熔断器
+	FailureCodes        []codes.Code  // 计为失败的错误码
+
+	// OnStateChange 状态变化回调，在释放熔断器锁之后同步调用，可以在回调中读取 Stats；
+	// 不同熔断器的回调可能并发执行，不应执行耗时操作
+	OnStateChange func(target, method string, from, to CircuitState)
+}
+
+// DefaultCircuitBreakerConfig 返回默认配置
+func DefaultCircuitBreakerConfig() *CircuitBreakerConfig {
+	return &CircuitBreakerConfig{
+		Window:              10 * time.Second,
+		Buckets:             10,
+		MinRequests:         20,
+		FailureRatio:        0.5,
+		ConsecutiveFailures: 10,
+		OpenTimeout:         5 * time.Second,
+		HalfOpenMaxRequests: 1,
+		FailureCodes: []codes.Code{
+			codes.Unavailable,
+			codes.DeadlineExceeded,
+			codes.Internal,
+			codes.Unknown,
+			codes.ResourceExhausted,
+		},
+	}
+}
+
+// CircuitBreakerRegistry 按 (目标地址, 方法) 维护熔断器
+type CircuitBreakerRegistry struct {
+	config *CircuitBreakerConfig
+
+	mu       sync.RWMutex
+	breakers map[circuitKey]*circuitBreaker
+}
+
+// circuitKey 熔断器的键
+type circuitKey struct {
+	target string
+	method string
+}
+
+// NewCircuitBreakerRegistry 创建熔断器集合
+func NewCircuitBreakerRegistry(config *CircuitBreakerConfig) *CircuitBreakerRegistry {
+	if config == nil {
+		config = DefaultCircuitBreakerConfig()
+	}
+	// 在副本上补全默认值，不修改调用方传入的配置
+	copied := *config
+	config = &copied
+	if config.Buckets <= 0 {
+		config.Buckets = 10
+	}
+	if config.Window/time.Duration(config.Buckets) <= 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+	return &CircuitBreakerRegistry{
+		config:   config,
+		breakers: make(map[circuitKey]*circuitBreaker),
+	}
+}
+
+// UnaryClientInterceptor 熔断拦截器，熔断器打开时直接返回 Unavailable
+func (r *CircuitBreakerRegistry) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		cb := r.breaker(cc.Target(), method)
+		generation, err := cb.allow()
+		if err != nil {
+			return err
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		cb.done(generation, !r.isFailure(err))
+		return err
+	}
+}
+
+// StreamClientInterceptor 流式请求熔断拦截器，只统计建流的结果
+func (r *CircuitBreakerRegistry) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cb := r.breaker(cc.Target(), method)
+		generation, err := cb.allow()
+		if err != nil {
+			return nil, err
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		cb.done(generation, !r.isFailure(err))
+		return cs, err
+	}
+}
+
+// State 返回熔断器当前状态，不存在时返回 CircuitClosed
+func (r *CircuitBreakerRegistry) State(target, method string) CircuitState {
+	r.mu.RLock()
+	cb, ok := r.breakers[circuitKey{target: target, method: method}]
+	r.mu.RUnlock()
+	if !ok {
+		return CircuitClosed
+	}
+	cb.mu.Lock()
+	defer cb.unlock()
+	cb.refreshLocked(time.Now())
+	return cb.state
+}
+
+// Stats 返回每个目标地址下各方法熔断器的状态和窗口内的请求数、失败数
+func (r *CircuitBreakerRegistry) Stats() map[string]interface{} {
+	var notify []func()
+	defer func() {
+		for _, fn := range notify {
+			fn()
+		}
+	}()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	stats := make(map[string]interface{})
+	for key, cb := range r.breakers {
+		methods, ok := stats[key.target].(map[string]interface{})
+		if !ok {
+			methods = make(map[string]interface{})
+			stats[key.target] = methods
+		}
+		cb.mu.Lock()
+		cb.refreshLocked(now)
+		requests, failures := cb.window.sum(now)
+		methods[key.method] = map[string]interface{}{
+			"state":    cb.state.String(),
+			"requests": requests,
+			"failures": failures,
+		}
+		// 状态变化回调在释放所有锁之后执行
+		notify = append(notify, cb.takeChangesLocked())
+		cb.mu.Unlock()
+	}
+	return stats
+}
+
+// breaker 获取熔断器，不存在时创建
+func (r *CircuitBreakerRegistry) breaker(target, method string) *circuitBreaker {
+	key := circuitKey{target: target, method: method}
+	r.mu.RLock()
+	cb, ok := r.breakers[key]
+	r.mu.RUnlock()
+	if ok {
+		return cb
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cb, ok = r.breakers[key]; ok {
+		return cb
+	}
+	cb = &circuitBreaker{
+		key:    key,
+		config: r.config,
+		window: newRollingWindow(r.config.Window, r.config.Buckets),
+	}
+	r.breakers[key] = cb
+	return cb
+}
+
+// isFailure 错误是否计为失败
+func (r *CircuitBreakerRegistry) isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := status.Code(err)
+	for _, c := range r.config.FailureCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// circuitBreaker 单个 (目标地址, 方法) 的熔断器
+type circuitBreaker struct {
+	key    circuitKey
+	config *CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       CircuitState
+	generation  uint64 // 每次状态变化加1，用于丢弃状态变化前发出的请求的结果
+	openedAt    time.Time
+	consecutive int // 连续失败次数
+	probes      int // 半开状态下正在进行的探测请求数
+	successes   int // 半开状态下成功的探测请求数
+	window      *rollingWindow
+	changes     []stateChange // 尚未通知的状态变化，释放锁之后回调
+}
+
+// stateChange 一次状态变化
+type stateChange struct {
+	from CircuitState
+	to   CircuitState
+}
+
+// allow 判断请求是否可以通过，返回当前的状态代数
+func (cb *circuitBreaker) allow() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	cb.refreshLocked(time.Now())
+	switch cb.state {
+	case CircuitOpen:
+		return 0, status.Errorf(codes.Unavailable, "circuit breaker open: target=%s, method=%s", cb.key.target, cb.key.method)
+	case CircuitHalfOpen:
+		if cb.probes >= cb.config.HalfOpenMaxRequests {
+			return 0, status.Errorf(codes.Unavailable, "circuit breaker half-open, probe in progress: target=%s, method=%s", cb.key.target, cb.key.method)
+		}
+		cb.probes++
+	}
+	return cb.generation, nil
+}
+
+// done 记录请求结果
+func (cb *circuitBreaker) done(generation uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.unlock()
+
+	if generation != cb.generation {
+		return
+	}
+	now := time.Now()
+
+	switch cb.state {
+	case CircuitHalfOpen:
+		cb.probes--
+		if !success {
+			cb.setStateLocked(CircuitOpen, now)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.config.HalfOpenMaxRequests {
+			cb.setStateLocked(CircuitClosed, now)
+		}
+	case CircuitClosed:
+		cb.window.add(now, success)
+		if success {
+			cb.consecutive = 0
+			return
+		}
+		cb.consecutive++
+		if cb.config.ConsecutiveFailures > 0 && cb.consecutive >= cb.config.ConsecutiveFailures {
+			cb.setStateLocked(CircuitOpen, now)
+			return
+		}
+		// FailureRatio 为0时不按失败率熔断，否则任意一次失败都会打开熔断器
+		if cb.config.FailureRatio <= 0 {
+			return
+		}
+		requests, failures := cb.window.sum(now)
+		if requests >= int64(cb.config.MinRequests) && float64(failures) >= cb.config.FailureRatio*float64(requests) {
+			cb.setStateLocked(CircuitOpen, now)
+		}
+	}
+}
+
+// refreshLocked 打开状态超时后进入半开状态
+func (cb *circuitBreaker) refreshLocked(now time.Time) {
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+		cb.setStateLocked(CircuitHalfOpen, now)
+	}
+}
+
+// setStateLocked 切换状态并重置统计
+func (cb *circuitBreaker) setStateLocked(state CircuitState, now time.Time) {
+	from := cb.state
+	cb.state = state
+	cb.generation++
+	cb.consecutive = 0
+	cb.probes = 0
+	cb.successes = 0
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+	if state == CircuitClosed {
+		cb.window.reset()
+	}
+	if cb.config.OnStateChange != nil {
+		cb.changes = append(cb.changes, stateChange{from: from, to: state})
+	}
+}
+
+// unlock 释放锁，然后通知锁内发生的状态变化
+// 回调可能读取 ConnPool.Stats 等需要熔断器锁的数据，在持有锁时调用会死锁
+func (cb *circuitBreaker) unlock() {
+	notify := cb.takeChangesLocked()
+	cb.mu.Unlock()
+	notify()
+}
+
+// takeChangesLocked 取出尚未通知的状态变化，返回执行回调的函数，调用方需持有锁
+func (cb *circuitBreaker) takeChangesLocked() func() {
+	changes := cb.changes
+	cb.changes = nil
+	return func() {
+		for _, c := range changes {
+			cb.config.OnStateChange(cb.key.target, cb.key.method, c.from, c.to)
+		}
+	}
+}
+
+// rollingWindow 按时间分桶的滚动窗口，统计请求数和失败数
+type rollingWindow struct {
+	bucketSize time.Duration
+	buckets    []windowBucket
+}
+
+// windowBucket 单个桶的统计
+type windowBucket struct {
+	start    int64 // 桶的起始时间，单位为 bucketSize
+	requests int64
+	failures int64
+}
+
+func newRollingWindow(window time.Duration, buckets int) *rollingWindow {
+	return &rollingWindow{
+		bucketSize: window / time.Duration(buckets),
+		buckets:    make([]windowBucket, buckets),
+	}
+}
+
+// add 记录一次请求
+func (w *rollingWindow) add(now time.Time, success bool) {
+	index := now.UnixNano() / int64(w.bucketSize)
+	bucket := &w.buckets[index%int64(len(w.buckets))]
+	if bucket.start != index {
+		*bucket = windowBucket{start: index}
+	}
+	bucket.requests++
+	if !success {
+		bucket.failures++
+	}
+}
+
+// sum 统计窗口内的请求数和失败数
+func (w *rollingWindow) sum(now time.Time) (requests, failures int64) {
+	index := now.UnixNano() / int64(w.bucketSize)
+	for _, bucket := range w.buckets {
+		if index-bucket.start < int64(len(w.buckets)) {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+	return requests, failures
+}
+
+// reset 清空窗口
+func (w *rollingWindow) reset() {
+	for i := range w.buckets {
+		w.buckets[i] = windowBucket{}
+	}
+}
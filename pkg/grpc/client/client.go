@@ -64,7 +64,11 @@ func NewClient(opts ...ClientOption) (Client, error) {
 		ConnMaxLifetime: options.Pool.ConnMaxLifetime,
 		ConnMaxIdleTime: options.Pool.ConnMaxIdleTime,
 		DialTimeout:     options.Timeout,
+
+		// 连接异常检测
+		OutlierDetection: options.OutlierDetection,
 	})
+	pool.breakers = options.CircuitBreakers
 
 	return &GrpcClient{
 		opts: options,
@@ -82,19 +86,34 @@ func (c *GrpcClient) getDialOptions() []grpc.DialOption {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
+	// 调用凭证
+	if c.opts.PerRPCCredentials != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(c.opts.PerRPCCredentials))
+	}
+
 	// KeepAlive配置
 	if c.opts.KeepAlive != nil {
 		opts = append(opts, grpc.WithKeepaliveParams(*c.opts.KeepAlive))
 	}
 
-	// 一元拦截器
-	if len(c.opts.UnaryInterceptors) > 0 {
-		opts = append(opts, grpc.WithUnaryInterceptor(attributes.ChainUnaryClient(c.opts.UnaryInterceptors...)))
+	// 一元拦截器，熔断和异常检测拦截器位于最内层
+	unaryInterceptors := c.opts.UnaryInterceptors[:len(c.opts.UnaryInterceptors):len(c.opts.UnaryInterceptors)]
+	streamInterceptors := c.opts.StreamInterceptors[:len(c.opts.StreamInterceptors):len(c.opts.StreamInterceptors)]
+	if c.opts.CircuitBreakers != nil {
+		unaryInterceptors = append(unaryInterceptors, c.opts.CircuitBreakers.UnaryClientInterceptor())
+		streamInterceptors = append(streamInterceptors, c.opts.CircuitBreakers.StreamClientInterceptor())
+	}
+	if c.opts.OutlierDetection != nil {
+		unaryInterceptors = append(unaryInterceptors, c.pool.outlierUnaryInterceptor())
+		streamInterceptors = append(streamInterceptors, c.pool.outlierStreamInterceptor())
+	}
+	if len(unaryInterceptors) > 0 {
+		opts = append(opts, grpc.WithUnaryInterceptor(attributes.ChainUnaryClient(unaryInterceptors...)))
 	}
 
 	// 流式拦截器
-	if len(c.opts.StreamInterceptors) > 0 {
-		opts = append(opts, grpc.WithStreamInterceptor(attributes.ChainStreamClient(c.opts.StreamInterceptors...)))
+	if len(streamInterceptors) > 0 {
+		opts = append(opts, grpc.WithStreamInterceptor(attributes.ChainStreamClient(streamInterceptors...)))
 	}
 
 	return opts
@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// CacheControlHeader 服务端通过响应头提示缓存时间的 metadata 键，
+// 支持 "max-age=60"、"stale-while-revalidate=30"、"no-store"、"no-cache"
+const CacheControlHeader = "cache-control"
+
+// CacheRule 响应缓存规则
+type CacheRule struct {
+	Methods              []string      // 适用的方法，支持通配符，只应配置只读方法
+	TTL                  time.Duration // 缓存时间，服务端返回 max-age 时以服务端为准；为0且服务端未提示时不缓存
+	StaleWhileRevalidate time.Duration // 过期后仍可返回旧响应的时间，期间在后台刷新
+}
+
+// ResponseCacheConfig 响应缓存配置
+type ResponseCacheConfig struct {
+	Rules          []CacheRule   // 按顺序匹配，使用第一个匹配的规则
+	MaxEntries     int           // 最大缓存条目数，超出时淘汰最久未使用的条目
+	MetadataKeys   []string      // 参与缓存键计算的 outgoing metadata 键，例如 "authorization"
+	IgnoreHeaders  bool          // 忽略服务端的 cache-control 提示
+	RefreshTimeout time.Duration // 后台刷新请求的超时
+}
+
+// DefaultResponseCacheConfig 返回默认配置
+func DefaultResponseCacheConfig(rules ...CacheRule) *ResponseCacheConfig {
+	return &ResponseCacheConfig{
+		Rules:          rules,
+		MaxEntries:     10000,
+		RefreshTimeout: 10 * time.Second,
+	}
+}
+
+// ResponseCache 客户端响应缓存
+// 以方法、目标地址和请求内容（确定性序列化）为键缓存一元请求的成功响应
+type ResponseCache struct {
+	config *ResponseCacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // 头部为最近使用的条目
+
+	hits      atomic.Int64
+	staleHits atomic.Int64
+	misses    atomic.Int64
+	refreshes atomic.Int64
+}
+
+// cacheEntry 缓存条目
+type cacheEntry struct {
+	key        string
+	method     string
+	value      proto.Message
+	expiresAt  time.Time
+	staleUntil time.Time
+	refreshing bool
+}
+
+// NewResponseCache 创建响应缓存
+func NewResponseCache(config *ResponseCacheConfig) *ResponseCache {
+	if config == nil {
+		config = DefaultResponseCacheConfig()
+	}
+	// 在副本上补全默认值，不修改调用方传入的配置
+	copied := *config
+	config = &copied
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 10000
+	}
+	if config.RefreshTimeout <= 0 {
+		config.RefreshTimeout = 10 * time.Second
+	}
+	return &ResponseCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// UnaryClientInterceptor 响应缓存拦截器
+func (c *ResponseCache) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		in, ok := req.(proto.Message)
+		out, ok2 := reply.(proto.Message)
+		rule := c.rule(method)
+		if !ok || !ok2 || rule == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		key, err := c.key(ctx, method, cc.Target(), in)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		now := time.Now()
+		c.mu.Lock()
+		if elem, ok := c.entries[key]; ok {
+			entry := elem.Value.(*cacheEntry)
+			switch {
+			case now.Before(entry.expiresAt):
+				c.lru.MoveToFront(elem)
+				value := entry.value
+				c.mu.Unlock()
+				c.hits.Add(1)
+				proto.Reset(out)
+				proto.Merge(out, value)
+				return nil
+			case now.Before(entry.staleUntil):
+				c.lru.MoveToFront(elem)
+				value := entry.value
+				refresh := !entry.refreshing
+				entry.refreshing = true
+				c.mu.Unlock()
+				c.staleHits.Add(1)
+				if refresh {
+					// 后台刷新在调用方返回后仍会使用请求，因此复制请求，并在当前 goroutine 中创建响应对象
+					go c.refresh(ctx, key, method, proto.Clone(in), out.ProtoReflect().New().Interface(), rule, cc, invoker, opts)
+				}
+				proto.Reset(out)
+				proto.Merge(out, value)
+				return nil
+			}
+		}
+		c.mu.Unlock()
+		c.misses.Add(1)
+
+		// 限制容量后再追加，避免写入调用方 opts 底层数组的空闲位置
+		var header metadata.MD
+		if err := invoker(ctx, method, req, reply, cc, append(opts[:len(opts):len(opts)], grpc.Header(&header))...); err != nil {
+			return err
+		}
+		c.store(key, method, out, rule, header)
+		return nil
+	}
+}
+
+// Invalidate 删除某个请求的缓存，target 为连接的目标地址（cc.Target()），metadata 键需要通过 ctx 提供
+func (c *ResponseCache) Invalidate(ctx context.Context, target, method string, req proto.Message) {
+	key, err := c.key(ctx, method, target, req)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeLocked(elem)
+	}
+}
+
+// InvalidateMethod 删除某个方法的全部缓存
+func (c *ResponseCache) InvalidateMethod(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if elem.Value.(*cacheEntry).method == method {
+			c.removeLocked(elem)
+		}
+		elem = next
+	}
+}
+
+// Purge 清空缓存
+func (c *ResponseCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// Stats 返回缓存命中、过期命中、未命中和后台刷新的次数
+func (c *ResponseCache) Stats() map[string]interface{} {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+	return map[string]interface{}{
+		"entries":    entries,
+		"hits":       c.hits.Load(),
+		"stale_hits": c.staleHits.Load(),
+		"misses":     c.misses.Load(),
+		"refreshes":  c.refreshes.Load(),
+	}
+}
+
+// refresh 在后台刷新过期的条目，刷新失败时保留旧条目直到 staleUntil
+// req 和 out 不能与调用方共享，调用方可能已经返回并复用它们
+func (c *ResponseCache) refresh(ctx context.Context, key, method string, req, out proto.Message, rule *CacheRule, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts []grpc.CallOption) {
+	c.refreshes.Add(1)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.config.RefreshTimeout)
+	defer cancel()
+
+	var header metadata.MD
+	if err := invoker(ctx, method, req, out, cc, append(detachedCallOptions(opts), grpc.Header(&header))...); err != nil {
+		log.Printf("Response cache refresh failed: method=%s, error=%v", method, err)
+		c.mu.Lock()
+		if elem, ok := c.entries[key]; ok {
+			elem.Value.(*cacheEntry).refreshing = false
+		}
+		c.mu.Unlock()
+		return
+	}
+	c.store(key, method, out, rule, header)
+}
+
+// store 按规则和服务端提示写入缓存
+func (c *ResponseCache) store(key, method string, value proto.Message, rule *CacheRule, header metadata.MD) {
+	ttl, swr := rule.TTL, rule.StaleWhileRevalidate
+	if !c.config.IgnoreHeaders {
+		var cacheable bool
+		ttl, swr, cacheable = parseCacheControl(header.Get(CacheControlHeader), ttl, swr)
+		if !cacheable {
+			ttl = 0
+		}
+	}
+	// 不可缓存或缓存时间为0（例如 max-age=0）时删除已有条目，
+	// 后台刷新的结果也走这里，删除条目同时结束了刷新状态
+	if ttl <= 0 {
+		c.mu.Lock()
+		if elem, ok := c.entries[key]; ok {
+			c.removeLocked(elem)
+		}
+		c.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	entry := &cacheEntry{
+		key:        key,
+		method:     method,
+		value:      proto.Clone(value),
+		expiresAt:  now.Add(ttl),
+		staleUntil: now.Add(ttl + swr),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.config.MaxEntries {
+		c.removeLocked(c.lru.Back())
+	}
+}
+
+// removeLocked 删除条目，调用方需持有锁
+func (c *ResponseCache) removeLocked(elem *list.Element) {
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+	c.lru.Remove(elem)
+}
+
+// rule 查找方法对应的缓存规则
+func (c *ResponseCache) rule(method string) *CacheRule {
+	for i := range c.config.Rules {
+		if attributes.MatchAny(c.config.Rules[i].Methods, method) {
+			return &c.config.Rules[i]
+		}
+	}
+	return nil
+}
+
+// key 计算缓存键，同一个缓存实例可能被多个连接共享，目标地址不同的响应不能混用
+func (c *ResponseCache) key(ctx context.Context, method, target string, req proto.Message) (string, error) {
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(target))
+	h.Write([]byte{0})
+	if len(c.config.MetadataKeys) > 0 {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		for _, k := range c.config.MetadataKeys {
+			h.Write([]byte(k))
+			h.Write([]byte{'='})
+			h.Write([]byte(strings.Join(md.Get(k), ",")))
+			h.Write([]byte{0})
+		}
+	}
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// parseCacheControl 解析服务端的 cache-control 提示，没有提示时返回配置的缓存时间
+func parseCacheControl(values []string, ttl, swr time.Duration) (time.Duration, time.Duration, bool) {
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(strings.ToLower(directive)), "=")
+			switch name {
+			case "no-store", "no-cache":
+				return 0, 0, false
+			case "max-age":
+				if seconds, err := strconv.Atoi(arg); err == nil && seconds >= 0 {
+					ttl = time.Duration(seconds) * time.Second
+				}
+			case "stale-while-revalidate":
+				if seconds, err := strconv.Atoi(arg); err == nil && seconds >= 0 {
+					swr = time.Duration(seconds) * time.Second
+				}
+			}
+		}
+	}
+	return ttl, swr, true
+}
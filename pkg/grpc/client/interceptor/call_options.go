@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// detachedCallOptions 去掉会写回调用方变量的选项（Header、Trailer、Peer），
+// 用于并发发出的多个请求，或调用方可能已经返回的后台请求
+func detachedCallOptions(opts []grpc.CallOption) []grpc.CallOption {
+	detached := make([]grpc.CallOption, 0, len(opts))
+	for _, opt := range opts {
+		switch opt.(type) {
+		case grpc.HeaderCallOption, grpc.TrailerCallOption, grpc.PeerCallOption:
+			continue
+		}
+		detached = append(detached, opt)
+	}
+	return detached
+}
+
+// deliverCallOutputs 将某一个请求的响应头、响应尾和对端信息写回调用方通过选项传入的变量
+func deliverCallOutputs(opts []grpc.CallOption, header, trailer metadata.MD, p *peer.Peer) {
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = header
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = trailer
+		case grpc.PeerCallOption:
+			if p.Addr != nil {
+				*o.PeerAddr = *p
+			}
+		}
+	}
+}
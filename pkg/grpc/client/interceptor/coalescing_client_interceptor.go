@@ -0,0 +1,210 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// CoalescingConfig 请求合并配置
+type CoalescingConfig struct {
+	// Methods 启用合并的方法，支持通配符，只应配置没有副作用的只读方法
+	Methods []string
+	// MetadataKeys 参与比较的 outgoing metadata 键，例如 "authorization"、"x-tenant-id"，
+	// 这些键的值不同的请求不会被合并，避免不同身份的调用方共享响应
+	MetadataKeys []string
+	// MaxTimeout 共享请求的最长执行时间，为0时使用 DefaultCoalescingMaxTimeout；
+	// 第一个调用方设置了更早的截止时间时使用调用方的截止时间
+	MaxTimeout time.Duration
+}
+
+// DefaultCoalescingMaxTimeout 共享请求默认的最长执行时间
+const DefaultCoalescingMaxTimeout = 30 * time.Second
+
+// Coalescer 请求合并
+// 同一时刻方法、目标地址、请求内容（确定性序列化）以及指定 metadata 都相同的调用只发出一次网络请求，
+// 所有等待者共享同一个响应。共享的请求不受某个调用方取消的影响，所有等待者都离开后才会被取消；
+// 共享请求的截止时间取第一个调用方的截止时间和 MaxTimeout 中较早的一个，后端无响应时不会一直占用合并条目
+// 共享请求使用第一个调用方的 CallOption，但不包括 grpc.Header、grpc.Trailer、grpc.Peer 等写回调用方变量的选项
+type Coalescer struct {
+	config *CoalescingConfig
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+
+	requests  atomic.Int64 // 启用合并的调用次数
+	network   atomic.Int64 // 实际发出的网络请求数
+	coalesced atomic.Int64 // 合并到已有请求的调用次数
+}
+
+// coalescedCall 正在进行的共享请求
+type coalescedCall struct {
+	done    chan struct{}
+	reply   proto.Message
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// NewCoalescer 创建请求合并
+func NewCoalescer(config *CoalescingConfig) *Coalescer {
+	if config == nil {
+		config = &CoalescingConfig{}
+	}
+	// 在副本上补全默认值，不修改调用方传入的配置
+	copied := *config
+	config = &copied
+	if config.MaxTimeout <= 0 {
+		config.MaxTimeout = DefaultCoalescingMaxTimeout
+	}
+	return &Coalescer{
+		config: config,
+		calls:  make(map[string]*coalescedCall),
+	}
+}
+
+// UnaryClientInterceptor 请求合并拦截器
+func (c *Coalescer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		in, ok := req.(proto.Message)
+		out, ok2 := reply.(proto.Message)
+		if !ok || !ok2 || !attributes.MatchAny(c.config.Methods, method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		key, err := c.key(ctx, method, cc.Target(), in)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		c.requests.Add(1)
+
+		c.mu.Lock()
+		call, exists := c.calls[key]
+		if exists {
+			call.waiters++
+			c.coalesced.Add(1)
+		} else {
+			// 共享请求保留调用方上下文中的值（包括 metadata），但不继承取消；
+			// 截止时间取第一个调用方的截止时间，且不超过 MaxTimeout
+			deadline := time.Now().Add(c.config.MaxTimeout)
+			if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+				deadline = d
+			}
+			callCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), deadline)
+			call = &coalescedCall{
+				done:    make(chan struct{}),
+				reply:   out.ProtoReflect().New().Interface(),
+				waiters: 1,
+				cancel:  cancel,
+			}
+			c.calls[key] = call
+			c.network.Add(1)
+			// 共享请求可能在第一个调用方返回后才发出或结束，因此复制请求，
+			// 并去掉 grpc.Header 等会写回第一个调用方变量的选项
+			go c.run(callCtx, key, call, method, proto.Clone(in), cc, invoker, detachedCallOptions(opts))
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+			if call.err != nil {
+				return call.err
+			}
+			proto.Reset(out)
+			proto.Merge(out, call.reply)
+			return nil
+		case <-ctx.Done():
+			c.mu.Lock()
+			call.waiters--
+			if call.waiters == 0 {
+				// 已取消的请求不能再被新的调用方合并
+				if c.calls[key] == call {
+					delete(c.calls, key)
+				}
+				call.cancel()
+			}
+			c.mu.Unlock()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// Stats 返回请求合并的统计信息
+func (c *Coalescer) Stats() map[string]interface{} {
+	c.mu.Lock()
+	inflight := len(c.calls)
+	c.mu.Unlock()
+	return map[string]interface{}{
+		"requests":      c.requests.Load(),
+		"network_calls": c.network.Load(),
+		"coalesced":     c.coalesced.Load(),
+		"inflight":      inflight,
+	}
+}
+
+// run 发出共享请求并通知所有等待者
+func (c *Coalescer) run(ctx context.Context, key string, call *coalescedCall, method string, req interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts []grpc.CallOption) {
+	call.err = invoker(ctx, method, req, call.reply, cc, opts...)
+
+	// 所有等待者离开时条目已被删除，此时同一个键可能已经对应新的共享请求
+	c.mu.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.mu.Unlock()
+
+	close(call.done)
+	call.cancel()
+}
+
+// key 计算请求的合并键
+func (c *Coalescer) key(ctx context.Context, method, target string, req proto.Message) (string, error) {
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(target))
+	h.Write([]byte{0})
+	if len(c.config.MetadataKeys) > 0 {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		for _, k := range c.config.MetadataKeys {
+			h.Write([]byte(k))
+			h.Write([]byte{'='})
+			h.Write([]byte(strings.Join(md.Get(k), ",")))
+			h.Write([]byte{0})
+		}
+	}
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// FallbackFunc 降级函数，返回替代的响应；返回错误时调用方收到该错误
+type FallbackFunc func(ctx context.Context, method string, req interface{}, err error) (interface{}, error)
+
+// FallbackRule 降级规则
+type FallbackRule struct {
+	Methods          []string     // 适用的方法，支持通配符
+	Codes            []codes.Code // 触发降级的错误码
+	Handler          FallbackFunc // 降级函数，优先于 SecondaryAddress
+	SecondaryAddress string       // 备用地址，Handler 为 nil 时从 Conns 获取该地址的连接重新发起调用
+}
+
+// FallbackConfig 降级配置
+type FallbackConfig struct {
+	Rules []FallbackRule // 按顺序匹配，使用第一个方法和错误码都匹配的规则
+	Conns ConnProvider   // 获取备用地址连接，使用 SecondaryAddress 时必须设置，通常为 client.Client
+}
+
+// FallbackPath 实际返回响应的路径
+type FallbackPath string
+
+const (
+	FallbackPathPrimary   FallbackPath = "primary"   // 原始调用
+	FallbackPathHandler   FallbackPath = "handler"   // 降级函数
+	FallbackPathSecondary FallbackPath = "secondary" // 备用地址
+)
+
+// FallbackResult 单次调用的降级记录
+type FallbackResult struct {
+	Path       FallbackPath // 返回响应的路径
+	PrimaryErr error        // 原始调用的错误，未降级时为 nil
+}
+
+type fallbackResultKey struct{}
+
+type fallbackActiveKey struct{}
+
+// WithFallbackResult 为本次调用设置降级记录，调用结束后可以从 result 中读取实际的响应路径
+func WithFallbackResult(ctx context.Context, result *FallbackResult) context.Context {
+	return context.WithValue(ctx, fallbackResultKey{}, result)
+}
+
+// Fallback 降级
+// 配置的方法返回指定错误码时，调用降级函数返回替代响应，或向备用地址重新发起调用
+type Fallback struct {
+	config *FallbackConfig
+
+	primary   atomic.Int64
+	handler   atomic.Int64
+	secondary atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewFallback 创建降级
+func NewFallback(config *FallbackConfig) *Fallback {
+	return &Fallback{config: config}
+}
+
+// UnaryClientInterceptor 降级拦截器
+func (f *Fallback) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		// 向备用地址发起的调用会再次经过拦截器链，不再降级
+		if ctx.Value(fallbackActiveKey{}) != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		result, _ := ctx.Value(fallbackResultKey{}).(*FallbackResult)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		rule := f.rule(method, err)
+		if rule == nil {
+			f.primary.Add(1)
+			if result != nil {
+				result.Path = FallbackPathPrimary
+			}
+			return err
+		}
+
+		path, fallbackErr := f.fallback(ctx, rule, method, req, reply, err, opts)
+		if fallbackErr != nil {
+			f.failed.Add(1)
+			if result != nil {
+				result.Path = FallbackPathPrimary
+				result.PrimaryErr = err
+			}
+			return fallbackErr
+		}
+		if result != nil {
+			result.Path = path
+			result.PrimaryErr = err
+		}
+		return nil
+	}
+}
+
+// Stats 返回各路径返回响应的次数，failed 为降级也失败的次数
+func (f *Fallback) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		string(FallbackPathPrimary):   f.primary.Load(),
+		string(FallbackPathHandler):   f.handler.Load(),
+		string(FallbackPathSecondary): f.secondary.Load(),
+		"failed":                      f.failed.Load(),
+	}
+}
+
+// rule 查找调用失败时适用的降级规则
+func (f *Fallback) rule(method string, err error) *FallbackRule {
+	if err == nil {
+		return nil
+	}
+	code := status.Code(err)
+	for i := range f.config.Rules {
+		rule := &f.config.Rules[i]
+		if attributes.MatchAny(rule.Methods, method) && containsCode(rule.Codes, code) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// fallback 执行降级，返回实际返回响应的路径
+func (f *Fallback) fallback(ctx context.Context, rule *FallbackRule, method string, req, reply interface{}, err error, opts []grpc.CallOption) (FallbackPath, error) {
+	if rule.Handler != nil {
+		resp, herr := rule.Handler(ctx, method, req, err)
+		if herr != nil {
+			return "", herr
+		}
+		if cerr := copyReply(reply, resp); cerr != nil {
+			return "", cerr
+		}
+		f.handler.Add(1)
+		return FallbackPathHandler, nil
+	}
+
+	if rule.SecondaryAddress == "" || f.config.Conns == nil {
+		return "", err
+	}
+	conn, cerr := f.config.Conns.GetConn(rule.SecondaryAddress, false)
+	if cerr != nil {
+		return "", err
+	}
+	defer f.config.Conns.ReleaseConn(conn)
+
+	if msg, ok := reply.(proto.Message); ok {
+		proto.Reset(msg)
+	}
+	if serr := conn.Invoke(context.WithValue(ctx, fallbackActiveKey{}, true), method, req, reply, opts...); serr != nil {
+		return "", serr
+	}
+	f.secondary.Add(1)
+	return FallbackPathSecondary, nil
+}
+
+// copyReply 将降级函数返回的响应复制到调用方的 reply 中
+func copyReply(reply, resp interface{}) error {
+	dst, ok := reply.(proto.Message)
+	if !ok {
+		return status.Error(codes.Internal, "fallback: reply is not a proto message")
+	}
+	src, ok := resp.(proto.Message)
+	if !ok || src == nil {
+		return status.Error(codes.Internal, "fallback: handler returned no proto response")
+	}
+	if src.ProtoReflect().Descriptor() != dst.ProtoReflect().Descriptor() {
+		return status.Errorf(codes.Internal, "fallback: handler returned %s, want %s",
+			src.ProtoReflect().Descriptor().FullName(), dst.ProtoReflect().Descriptor().FullName())
+	}
+	proto.Reset(dst)
+	proto.Merge(dst, src)
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/fault"
+	"google.golang.org/grpc"
+)
+
+// FaultClientInterceptor 创建一个故障注入拦截器，在发出请求前按规则注入延迟和错误，
+// 用于在不影响真实依赖的情况下验证重试、熔断等逻辑
+// 客户端只使用配置的规则，fault.HeaderKey 会原样发送给服务端，由服务端注入
+func FaultClientInterceptor(injector *fault.Injector) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := injector.Inject(ctx, method, nil); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamFaultClientInterceptor 创建一个流式请求故障注入拦截器，故障在建流时注入
+func StreamFaultClientInterceptor(injector *fault.Injector) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := injector.Inject(ctx, method, nil); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
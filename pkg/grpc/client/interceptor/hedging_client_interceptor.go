@@ -0,0 +1,275 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// ConnProvider 连接提供者，client.Client 实现了该接口
+type ConnProvider interface {
+	GetConn(address string, isStream bool) (*grpc.ClientConn, error)
+	ReleaseConn(*grpc.ClientConn)
+}
+
+// HedgingConfig 对冲请求配置
+type HedgingConfig struct {
+	// Methods 启用对冲的方法，支持通配符，只应配置幂等的方法
+	Methods []string
+	// MaxAttempts 最多同时发出的请求数（包含原始请求）
+	MaxAttempts int
+	// Delay 每次发出对冲请求前等待的时间
+	Delay time.Duration
+	// Percentile 大于0时按方法观测到的延迟分位数（例如95）作为等待时间，样本不足时使用 Delay
+	Percentile float64
+	// NonFatalCodes 不终止对冲的错误码，某个请求返回这些错误时立即发出下一个对冲请求，其他错误直接返回
+	NonFatalCodes []codes.Code
+	// Conns 为 nil 时对冲请求与原始请求使用同一个连接，否则从中获取连接（可能是连接池中的另一个连接）
+	Conns ConnProvider
+}
+
+// DefaultHedgingConfig 返回默认配置：最多3个请求，间隔50毫秒
+func DefaultHedgingConfig(methods ...string) *HedgingConfig {
+	return &HedgingConfig{
+		Methods:       methods,
+		MaxAttempts:   3,
+		Delay:         50 * time.Millisecond,
+		NonFatalCodes: []codes.Code{codes.Unavailable},
+	}
+}
+
+// Hedger 对冲请求
+// 原始请求在等待时间内未返回时再发出相同的请求，返回最先成功的响应并取消其余请求，用于降低读请求的长尾延迟
+type Hedger struct {
+	config    *HedgingConfig
+	latencies sync.Map // 方法到 *latencyWindow 的映射
+
+	calls       atomic.Int64 // 启用对冲的调用次数
+	hedgedCalls atomic.Int64 // 实际发出了对冲请求的调用次数
+	hedgesSent  atomic.Int64 // 发出的对冲请求数
+	hedgeWins   atomic.Int64 // 对冲请求先于原始请求成功的次数
+}
+
+// NewHedger 创建对冲请求
+func NewHedger(config *HedgingConfig) *Hedger {
+	if config == nil {
+		config = DefaultHedgingConfig()
+	}
+	return &Hedger{config: config}
+}
+
+// hedgeResult 单个请求的结果
+type hedgeResult struct {
+	attempt int
+	reply   proto.Message
+	header  metadata.MD
+	trailer metadata.MD
+	remote  peer.Peer
+	err     error
+}
+
+// UnaryClientInterceptor 对冲请求拦截器
+func (h *Hedger) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		msg, ok := reply.(proto.Message)
+		if !ok || h.config.MaxAttempts < 2 || !attributes.MatchAny(h.config.Methods, method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		h.calls.Add(1)
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// 每个请求使用独立的响应对象、响应头和响应尾，最终返回的请求的结果再写回调用方，
+		// 避免多个请求并发写入调用方通过 grpc.Header 等选项传入的变量
+		attemptOpts := detachedCallOptions(opts)
+		results := make(chan hedgeResult, h.config.MaxAttempts)
+		launch := func(attempt int) {
+			// 响应对象在当前 goroutine 中创建，请求 goroutine 不再访问调用方的 reply
+			r := hedgeResult{attempt: attempt, reply: msg.ProtoReflect().New().Interface()}
+			go func() {
+				conn, release := h.conn(cc, attempt)
+				defer release()
+				callOpts := append(attemptOpts[:len(attemptOpts):len(attemptOpts)],
+					grpc.Header(&r.header), grpc.Trailer(&r.trailer), grpc.Peer(&r.remote))
+				r.err = invoker(retryAttemptContext(ctx, attempt), method, req, r.reply, conn, callOpts...)
+				results <- r
+			}()
+		}
+
+		delay := h.delay(method)
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		start := time.Now()
+		launch(0)
+		sent, pending := 1, 1
+		hedge := func() {
+			if sent == 1 {
+				h.hedgedCalls.Add(1)
+			}
+			h.hedgesSent.Add(1)
+			launch(sent)
+			sent++
+			pending++
+			if sent < h.config.MaxAttempts {
+				timer.Reset(delay)
+			}
+		}
+
+		var lastErr error
+		for {
+			select {
+			case <-timer.C:
+				if sent < h.config.MaxAttempts {
+					hedge()
+				}
+			case r := <-results:
+				pending--
+				if r.err == nil {
+					if r.attempt > 0 {
+						h.hedgeWins.Add(1)
+					}
+					// 记录原始请求的延迟；对冲请求胜出时原始请求还没有返回，已等待的时间是它延迟的下限，
+					// 只记录胜出请求自身的延迟会让分位数越来越小，对冲越来越激进
+					h.record(method, time.Since(start))
+					deliverCallOutputs(opts, r.header, r.trailer, &r.remote)
+					proto.Reset(msg)
+					proto.Merge(msg, r.reply)
+					return nil
+				}
+				lastErr = r.err
+				if !containsCode(h.config.NonFatalCodes, status.Code(r.err)) {
+					deliverCallOutputs(opts, r.header, r.trailer, &r.remote)
+					return r.err
+				}
+				if sent < h.config.MaxAttempts {
+					timer.Stop()
+					hedge()
+				} else if pending == 0 {
+					deliverCallOutputs(opts, r.header, r.trailer, &r.remote)
+					return lastErr
+				}
+			case <-ctx.Done():
+				return status.FromContextError(ctx.Err()).Err()
+			}
+		}
+	}
+}
+
+// Stats 返回对冲请求的统计信息
+func (h *Hedger) Stats() map[string]interface{} {
+	delays := make(map[string]int64)
+	h.latencies.Range(func(key, value interface{}) bool {
+		delays[key.(string)] = time.Duration(value.(*latencyWindow).cached.Load()).Milliseconds()
+		return true
+	})
+	return map[string]interface{}{
+		"calls":          h.calls.Load(),
+		"hedged_calls":   h.hedgedCalls.Load(),
+		"hedges_sent":    h.hedgesSent.Load(),
+		"hedge_wins":     h.hedgeWins.Load(),
+		"delays_ms":      delays,
+		"max_attempts":   h.config.MaxAttempts,
+		"fixed_delay_ms": h.config.Delay.Milliseconds(),
+	}
+}
+
+// conn 获取对冲请求使用的连接，原始请求或获取失败时使用原连接
+func (h *Hedger) conn(cc *grpc.ClientConn, attempt int) (*grpc.ClientConn, func()) {
+	if attempt == 0 || h.config.Conns == nil {
+		return cc, func() {}
+	}
+	conn, err := h.config.Conns.GetConn(cc.Target(), false)
+	if err != nil {
+		return cc, func() {}
+	}
+	return conn, func() { h.config.Conns.ReleaseConn(conn) }
+}
+
+// delay 返回方法的对冲等待时间
+func (h *Hedger) delay(method string) time.Duration {
+	if h.config.Percentile > 0 {
+		if v, ok := h.latencies.Load(method); ok {
+			if d := v.(*latencyWindow).cached.Load(); d > 0 {
+				return time.Duration(d)
+			}
+		}
+	}
+	return h.config.Delay
+}
+
+// record 记录原始请求的延迟，用于计算分位数
+func (h *Hedger) record(method string, elapsed time.Duration) {
+	if h.config.Percentile <= 0 {
+		return
+	}
+	v, _ := h.latencies.LoadOrStore(method, &latencyWindow{samples: make([]time.Duration, 0, latencyWindowSize)})
+	v.(*latencyWindow).record(elapsed, h.config.Percentile)
+}
+
+const (
+	latencyWindowSize = 500 // 每个方法保留的延迟样本数
+	latencyMinSamples = 20  // 计算分位数所需的最少样本数
+)
+
+// latencyWindow 最近的延迟样本，每累计 latencyMinSamples 个样本重新计算一次分位数
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	count   int
+	cached  atomic.Int64
+}
+
+func (w *latencyWindow) record(d time.Duration, percentile float64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, d)
+	} else {
+		w.samples[w.next] = d
+		w.next = (w.next + 1) % latencyWindowSize
+	}
+	w.count++
+	if w.count%latencyMinSamples != 0 {
+		return
+	}
+
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := int(float64(len(sorted)-1) * percentile / 100)
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	w.cached.Store(int64(sorted[index]))
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TokenExchanger 令牌交换接口
+// 用上游调用方的令牌换取访问下游服务的令牌，例如 OAuth2 token exchange
+type TokenExchanger interface {
+	Exchange(ctx context.Context, subjectToken string, principal *attributes.Principal, target string) (string, error)
+}
+
+// TokenExchangerFunc 函数形式的 TokenExchanger
+type TokenExchangerFunc func(ctx context.Context, subjectToken string, principal *attributes.Principal, target string) (string, error)
+
+// Exchange 实现 TokenExchanger
+func (f TokenExchangerFunc) Exchange(ctx context.Context, subjectToken string, principal *attributes.Principal, target string) (string, error) {
+	return f(ctx, subjectToken, principal, target)
+}
+
+// OnBehalfOfConfig 调用方身份透传配置
+type OnBehalfOfConfig struct {
+	AllowedTargets       []string       // 允许接收身份的下游地址（cc.Target()），支持通配符，为空时不向任何下游透传
+	Exchanger            TokenExchanger // 令牌交换器，为空时直接透传原始令牌
+	TokenMetadataKey     string         // 上下游令牌所在的 metadata 键，默认 authorization
+	PrincipalMetadataKey string         // 透传调用方主体标识的 metadata 键，默认 x-on-behalf-of
+}
+
+// DefaultOnBehalfOfConfig 返回默认配置
+func DefaultOnBehalfOfConfig(allowedTargets ...string) *OnBehalfOfConfig {
+	return &OnBehalfOfConfig{
+		AllowedTargets:       allowedTargets,
+		TokenMetadataKey:     "authorization",
+		PrincipalMetadataKey: "x-on-behalf-of",
+	}
+}
+
+// OnBehalfOfClientInterceptor 创建一个调用方身份透传拦截器
+// 在服务端处理请求的过程中调用下游服务时，从服务端上下文读取调用方的身份和令牌，
+// 写入下游请求的 metadata，只有白名单内的下游地址才会收到
+func OnBehalfOfClientInterceptor(config *OnBehalfOfConfig) grpc.UnaryClientInterceptor {
+	config = normalizeOnBehalfOfConfig(config)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := onBehalfOfContext(ctx, config, cc.Target())
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamOnBehalfOfClientInterceptor 创建一个流式调用方身份透传拦截器
+func StreamOnBehalfOfClientInterceptor(config *OnBehalfOfConfig) grpc.StreamClientInterceptor {
+	config = normalizeOnBehalfOfConfig(config)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := onBehalfOfContext(ctx, config, cc.Target())
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func normalizeOnBehalfOfConfig(config *OnBehalfOfConfig) *OnBehalfOfConfig {
+	if config == nil {
+		config = DefaultOnBehalfOfConfig()
+	}
+	if config.TokenMetadataKey == "" {
+		config.TokenMetadataKey = "authorization"
+	}
+	if config.PrincipalMetadataKey == "" {
+		config.PrincipalMetadataKey = "x-on-behalf-of"
+	}
+	return config
+}
+
+// onBehalfOfContext 将上游身份写入下游请求的 outgoing metadata
+func onBehalfOfContext(ctx context.Context, config *OnBehalfOfConfig, target string) (context.Context, error) {
+	if !attributes.MatchAny(config.AllowedTargets, target) {
+		return ctx, nil
+	}
+
+	principal, _ := attributes.PrincipalFromContext(ctx)
+
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if tokens := md.Get(config.TokenMetadataKey); len(tokens) > 0 {
+			token = tokens[0]
+		}
+	}
+
+	if config.Exchanger != nil && (token != "" || principal != nil) {
+		exchanged, err := config.Exchanger.Exchange(ctx, token, principal, target)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, "token exchange failed: %v", err)
+		}
+		token = exchanged
+	}
+
+	// 下游令牌覆盖已有的同名 metadata，避免同时携带服务自身的凭证和调用方令牌
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	if token != "" {
+		md.Set(config.TokenMetadataKey, token)
+	}
+	if principal != nil && principal.Subject != "" {
+		md.Set(config.PrincipalMetadataKey, principal.Subject)
+	}
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// PriorityClientInterceptor 创建一个请求优先级拦截器
+// 通过 attributes.ContextWithPriority 为单次调用设置优先级，未设置时不添加 metadata（服务端按默认优先级处理）
+func PriorityClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(priorityOutgoingContext(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamPriorityClientInterceptor 创建一个流式请求优先级拦截器
+func StreamPriorityClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(priorityOutgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// priorityOutgoingContext 将上下文中的优先级写入 outgoing metadata
+func priorityOutgoingContext(ctx context.Context) context.Context {
+	p, ok := attributes.PriorityFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(attributes.PriorityMetadataKey, p.String())
+	return metadata.NewOutgoingContext(ctx, md)
+}
@@ -20,28 +20,308 @@ package interceptor
 
 import (
 	"context"
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
+// RetryAttemptMetadataKey 重试请求携带的 metadata 键，取值为此前已经尝试的次数，首次请求不携带
+const RetryAttemptMetadataKey = "x-retry-attempt"
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	Methods           []string      // 适用的方法，支持通配符
+	MaxAttempts       int           // 最大尝试次数（包含首次请求）
+	RetryableCodes    []codes.Code  // 可重试的错误码
+	InitialBackoff    time.Duration // 首次重试前的等待时间
+	MaxBackoff        time.Duration // 等待时间的上限
+	BackoffMultiplier float64       // 每次重试等待时间的增长倍数
+	Jitter            float64       // 等待时间的随机抖动比例，取值 [0, 1]，避免大量客户端同时重试
+}
+
+// RetryConfig 重试配置
+type RetryConfig struct {
+	// Policies 按方法配置的重试策略，按顺序匹配，使用第一个匹配的策略
+	Policies []RetryPolicy
+	// Default 没有匹配的策略时使用的重试策略，Methods 字段被忽略
+	Default RetryPolicy
+	// IdempotentMethods 幂等方法，支持通配符；只有幂等方法会按 RetryableCodes 重试，其他方法不重试
+	IdempotentMethods []string
+	// Budget 重试预算，为 nil 时不限制；同一个客户端的所有调用应共享同一个预算
+	Budget *RetryBudget
+}
+
+// DefaultRetryConfig 返回默认配置：最多尝试3次，只重试 Unavailable，指数退避并带20%抖动
+func DefaultRetryConfig(idempotentMethods ...string) *RetryConfig {
+	return &RetryConfig{
+		Default: RetryPolicy{
+			MaxAttempts:       3,
+			RetryableCodes:    []codes.Code{codes.Unavailable},
+			InitialBackoff:    100 * time.Millisecond,
+			MaxBackoff:        2 * time.Second,
+			BackoffMultiplier: 2,
+			Jitter:            0.2,
+		},
+		IdempotentMethods: idempotentMethods,
+		Budget:            NewRetryBudget(0.1, 10, 10*time.Second),
+	}
+}
+
 // RetryClientInterceptor 重试拦截器
+// maxRetries 为最大尝试次数，所有方法都视为幂等，只重试 Unavailable 错误；
+// 服务端返回的错误携带 RetryInfo 时，按服务端建议的时间（不超过 MaxBackoff）等待后重试
+// 需要按方法配置、幂等控制或重试预算时使用 RetryPolicyClientInterceptor
 func RetryClientInterceptor(maxRetries int) grpc.UnaryClientInterceptor {
+	config := DefaultRetryConfig("*")
+	config.Default.MaxAttempts = maxRetries
+	config.Budget = nil
+	return RetryPolicyClientInterceptor(config)
+}
+
+// RetryPolicyClientInterceptor 按重试策略重试的拦截器
+// 等待期间调用方取消或剩余截止时间不足时立即返回最后一次的错误
+func RetryPolicyClientInterceptor(config *RetryConfig) grpc.UnaryClientInterceptor {
+	r := newRetryer(config)
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := r.policy(method)
+		r.recordRequest()
+
 		var err error
-		for i := 0; i < maxRetries; i++ {
-			err = invoker(ctx, method, req, reply, cc, opts...)
+		for attempt := 0; ; attempt++ {
+			err = invoker(retryAttemptContext(ctx, attempt), method, req, reply, cc, opts...)
 			if err == nil {
 				return nil
 			}
-			if status.Code(err) == codes.Unavailable {
-				time.Sleep(time.Duration(i+1) * 100 * time.Millisecond)
-				continue
+			delay, ok := r.retryDelay(ctx, method, policy, attempt, err)
+			if !ok {
+				return err
 			}
-			return err
+			if !waitRetry(ctx, delay) {
+				return err
+			}
+		}
+	}
+}
+
+// retryer 重试策略的运行时状态
+type retryer struct {
+	config  *RetryConfig
+	methods sync.Map // 方法到策略的映射缓存
+}
+
+func newRetryer(config *RetryConfig) *retryer {
+	if config == nil {
+		config = DefaultRetryConfig()
+	}
+	return &retryer{config: config}
+}
+
+// policy 查找方法对应的重试策略
+func (r *retryer) policy(method string) *RetryPolicy {
+	if v, ok := r.methods.Load(method); ok {
+		return v.(*RetryPolicy)
+	}
+
+	policy := &r.config.Default
+	for i := range r.config.Policies {
+		if attributes.MatchAny(r.config.Policies[i].Methods, method) {
+			policy = &r.config.Policies[i]
+			break
+		}
+	}
+	r.methods.Store(method, policy)
+	return policy
+}
+
+// recordRequest 记录一次新的调用（不含重试），用于计算重试预算
+func (r *retryer) recordRequest() {
+	if r.config.Budget != nil {
+		r.config.Budget.recordRequest()
+	}
+}
+
+// retryDelay 判断第 attempt 次尝试（从0开始）失败后是否重试，返回重试前需要等待的时间
+func (r *retryer) retryDelay(ctx context.Context, method string, policy *RetryPolicy, attempt int, err error) (time.Duration, bool) {
+	if attempt+1 >= policy.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+
+	if !attributes.MatchAny(r.config.IdempotentMethods, method) || !containsCode(policy.RetryableCodes, status.Code(err)) {
+		return 0, false
+	}
+
+	// 服务端通过 RetryInfo 建议了等待时间时优先使用，但不超过 MaxBackoff，避免异常的建议值让调用长时间挂起
+	delay, ok := retryInfoDelay(err)
+	if !ok {
+		delay = policy.backoff(attempt)
+	} else if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+
+	// 等待时间超过剩余的截止时间时，重试已经没有意义
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return 0, false
+	}
+	if r.config.Budget != nil && !r.config.Budget.tryRetry() {
+		return 0, false
+	}
+	return delay, true
+}
+
+// backoff 计算第 attempt 次尝试失败后的退避时间
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// waitRetry 等待重试，调用方取消时返回 false
+func waitRetry(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// retryAttemptContext 在重试请求的 metadata 中写入此前已尝试的次数
+func retryAttemptContext(ctx context.Context, attempt int) context.Context {
+	if attempt == 0 {
+		return ctx
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(RetryAttemptMetadataKey, strconv.Itoa(attempt))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// containsCode 错误码是否在列表中
+func containsCode(list []codes.Code, code codes.Code) bool {
+	for _, c := range list {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// retryInfoDelay 读取错误中服务端建议的重试等待时间
+func retryInfoDelay(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// RetryBudget 重试预算，限制重试请求占总请求的比例，避免下游故障时重试放大流量形成重试风暴
+// 时间窗口内允许的重试次数为 ratio*请求数 + minRetriesPerSecond*窗口秒数
+type RetryBudget struct {
+	ratio      float64
+	minRetries float64
+
+	mu      sync.Mutex
+	buckets []retryBucket // 按秒划分的环形窗口
+}
+
+// retryBucket 一秒内的请求数和重试数
+type retryBucket struct {
+	second   int64
+	requests int64
+	retries  int64
+}
+
+// NewRetryBudget 创建重试预算
+func NewRetryBudget(ratio float64, minRetriesPerSecond int, window time.Duration) *RetryBudget {
+	seconds := int(window / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &RetryBudget{
+		ratio:      ratio,
+		minRetries: float64(minRetriesPerSecond * seconds),
+		buckets:    make([]retryBucket, seconds),
+	}
+}
+
+// Stats 返回时间窗口内的请求数、重试数
+func (b *RetryBudget) Stats() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	requests, retries := b.sumLocked(time.Now().Unix())
+	return map[string]interface{}{
+		"requests": requests,
+		"retries":  retries,
+		"allowed":  int64(b.minRetries + b.ratio*float64(requests)),
+	}
+}
+
+// recordRequest 记录一次请求
+func (b *RetryBudget) recordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucketLocked(time.Now().Unix()).requests++
+}
+
+// tryRetry 预算充足时占用一次重试并返回 true
+func (b *RetryBudget) tryRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now().Unix()
+	requests, retries := b.sumLocked(now)
+	if float64(retries) >= b.minRetries+b.ratio*float64(requests) {
+		return false
+	}
+	b.bucketLocked(now).retries++
+	return true
+}
+
+// bucketLocked 返回当前秒对应的桶，过期的桶被清零
+func (b *RetryBudget) bucketLocked(now int64) *retryBucket {
+	bucket := &b.buckets[now%int64(len(b.buckets))]
+	if bucket.second != now {
+		*bucket = retryBucket{second: now}
+	}
+	return bucket
+}
+
+// sumLocked 统计时间窗口内的请求数和重试数
+func (b *RetryBudget) sumLocked(now int64) (requests, retries int64) {
+	window := int64(len(b.buckets))
+	for _, bucket := range b.buckets {
+		if now-bucket.second < window {
+			requests += bucket.requests
+			retries += bucket.retries
 		}
-		return err
 	}
+	return requests, retries
 }
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// SigningClientInterceptor 创建一个请求签名拦截器
+// 使用共享密钥对 method、时间戳、随机数和请求体摘要做 HMAC 签名，服务端使用 SignatureVerifier 校验
+// 注意：每次发送都会生成新的随机数，因此需要放在重试拦截器之后，保证每次重试都重新签名
+// 限制：服务端对解码后的请求重新做确定性序列化再校验，而不是校验客户端发送的原始字节。
+// 客户端的 proto 定义比服务端新（例如新增了字段）时，服务端重新序列化得到的字节可能不同，
+// 此时请求以 FailedPrecondition（attributes.ErrSignatureUnknownFields）被拒绝，而不是 invalid signature；
+// 升级 proto 时应先发布服务端，再发布使用新字段的客户端
+func SigningClientInterceptor(secret []byte) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		payload, err := attributes.SignaturePayload(req)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		ctx, err = signOutgoingContext(ctx, secret, method, payload)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamSigningClientInterceptor 创建一个流式请求签名拦截器
+// 流在建立时还没有请求消息，只对 method、时间戳和随机数签名
+func StreamSigningClientInterceptor(secret []byte) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := signOutgoingContext(ctx, secret, method, nil)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// signOutgoingContext 生成签名并写入 outgoing metadata
+func signOutgoingContext(ctx context.Context, secret []byte, method string, payload []byte) (context.Context, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate nonce: %v", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	nonceStr := hex.EncodeToString(nonce)
+	signature := attributes.ComputeSignature(secret, method, timestamp, nonceStr, payload)
+
+	return metadata.AppendToOutgoingContext(ctx,
+		attributes.SignatureMetadataKey, signature,
+		attributes.SignatureTimestampMetadataKey, timestamp,
+		attributes.SignatureNonceMetadataKey, nonceStr,
+	), nil
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamRetryConfig 流式请求重试配置
+type StreamRetryConfig struct {
+	// Retry 重试策略，与一元请求共用，为 nil 时使用 DefaultRetryConfig()
+	Retry *RetryConfig
+	// MaxBufferedMessages 为重放而缓存的已发送消息数上限，为0时只重试建流；
+	// 收到第一条响应或缓存超过上限后不再重放，此后的失败直接返回给调用方
+	MaxBufferedMessages int
+}
+
+// DefaultStreamRetryConfig 返回默认配置，最多缓存16条已发送消息
+func DefaultStreamRetryConfig(idempotentMethods ...string) *StreamRetryConfig {
+	return &StreamRetryConfig{
+		Retry:               DefaultRetryConfig(idempotentMethods...),
+		MaxBufferedMessages: 16,
+	}
+}
+
+// StreamRetryClientInterceptor 创建一个流式请求重试拦截器
+// 建流失败时按重试策略重新建流；在收到第一条响应之前流失败时，重新建流并重放已发送的消息，
+// 对调用方透明。服务端流式请求的请求消息同样会被缓存，因此也能在收到响应前透明重试
+func StreamRetryClientInterceptor(config *StreamRetryConfig) grpc.StreamClientInterceptor {
+	if config == nil {
+		config = DefaultStreamRetryConfig()
+	}
+	r := newRetryer(config.Retry)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		s := &retryClientStream{
+			ctx:         ctx,
+			desc:        desc,
+			cc:          cc,
+			method:      method,
+			streamer:    streamer,
+			opts:        opts,
+			retryer:     r,
+			policy:      r.policy(method),
+			maxBuffered: config.MaxBufferedMessages,
+			replayable:  config.MaxBufferedMessages > 0,
+		}
+		r.recordRequest()
+		if err := s.establish(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// retryClientStream 支持重新建流和重放的 ClientStream
+type retryClientStream struct {
+	grpc.ClientStream // 当前使用的流
+
+	ctx         context.Context
+	desc        *grpc.StreamDesc
+	cc          *grpc.ClientConn
+	method      string
+	streamer    grpc.Streamer
+	opts        []grpc.CallOption
+	retryer     *retryer
+	policy      *RetryPolicy
+	maxBuffered int
+
+	mu         sync.Mutex
+	attempt    int
+	buffer     []interface{} // 已发送的消息，用于重放
+	replayable bool          // 尚未收到响应且缓存未溢出
+	broken     bool          // 当前流发送失败（io.EOF），等待 RecvMsg 获取错误后决定是否重试
+	closeSent  bool
+}
+
+// establish 建流，失败时按重试策略重试，调用方需持有锁或尚未发布该流
+func (s *retryClientStream) establish() error {
+	for {
+		cs, err := s.streamer(retryAttemptContext(s.ctx, s.attempt), s.desc, s.cc, s.method, s.opts...)
+		if err == nil {
+			s.ClientStream = cs
+			s.broken = false
+			return nil
+		}
+		delay, ok := s.retryer.retryDelay(s.ctx, s.method, s.policy, s.attempt, err)
+		if !ok || !waitRetry(s.ctx, delay) {
+			return err
+		}
+		s.attempt++
+	}
+}
+
+// current 返回当前使用的流
+func (s *retryClientStream) current() grpc.ClientStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ClientStream
+}
+
+// SendMsg 发送消息，可重放时缓存消息副本
+func (s *retryClientStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	if s.replayable {
+		if len(s.buffer) < s.maxBuffered {
+			s.buffer = append(s.buffer, cloneMessage(m))
+		} else {
+			s.replayable = false
+			s.buffer = nil
+		}
+	}
+	if s.broken {
+		replayable := s.replayable
+		s.mu.Unlock()
+		if replayable {
+			// 消息已缓存，流恢复后会被重放
+			return nil
+		}
+		return io.EOF
+	}
+	cs := s.ClientStream
+	s.mu.Unlock()
+
+	err := cs.SendMsg(m)
+	if err == io.EOF {
+		// 流已被服务端结束，真正的错误需要通过 RecvMsg 获取，可重放时暂时吞掉错误等待重试
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.replayable && cs == s.ClientStream {
+			s.broken = true
+			return nil
+		}
+	}
+	return err
+}
+
+// CloseSend 关闭发送方向，重新建流后会再次调用
+func (s *retryClientStream) CloseSend() error {
+	s.mu.Lock()
+	s.closeSent = true
+	if s.broken {
+		s.mu.Unlock()
+		return nil
+	}
+	cs := s.ClientStream
+	s.mu.Unlock()
+	return cs.CloseSend()
+}
+
+// RecvMsg 接收消息，在收到第一条响应前失败时重新建流并重放
+func (s *retryClientStream) RecvMsg(m interface{}) error {
+	for {
+		cs := s.current()
+		err := cs.RecvMsg(m)
+		if err == nil {
+			s.mu.Lock()
+			s.replayable = false
+			s.buffer = nil
+			s.mu.Unlock()
+			return nil
+		}
+		if err == io.EOF {
+			return err
+		}
+		if err = s.retry(cs, err); err != nil {
+			return err
+		}
+	}
+}
+
+// retry 判断 cs 的失败是否可以重试，可以时重新建流并重放已发送的消息，不能重试时返回需要返回给调用方的错误
+func (s *retryClientStream) retry(cs grpc.ClientStream, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.replayable || cs != s.ClientStream {
+		return err
+	}
+	delay, ok := s.retryer.retryDelay(s.ctx, s.method, s.policy, s.attempt, err)
+	if !ok || !waitRetry(s.ctx, delay) {
+		return err
+	}
+	s.attempt++
+	if err := s.establish(); err != nil {
+		return err
+	}
+
+	for _, msg := range s.buffer {
+		// 重放失败（io.EOF）时新流已经结束，由下一次 RecvMsg 获取错误
+		if s.ClientStream.SendMsg(msg) != nil {
+			return nil
+		}
+	}
+	if s.closeSent {
+		_ = s.ClientStream.CloseSend()
+	}
+	return nil
+}
+
+// Header 返回当前流的响应头
+func (s *retryClientStream) Header() (metadata.MD, error) {
+	return s.current().Header()
+}
+
+// Trailer 返回当前流的响应尾
+func (s *retryClientStream) Trailer() metadata.MD {
+	return s.current().Trailer()
+}
+
+// Context 返回当前流的上下文
+func (s *retryClientStream) Context() context.Context {
+	return s.current().Context()
+}
+
+// cloneMessage 复制待缓存的消息，避免调用方发送后复用消息对象导致重放内容错误
+func cloneMessage(m interface{}) interface{} {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Clone(msg)
+	}
+	return m
+}
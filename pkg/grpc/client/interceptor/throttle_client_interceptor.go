@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AdaptiveThrottleConfig 客户端自适应限流配置
+type AdaptiveThrottleConfig struct {
+	// K 倍数，取值越小越激进；为2时客户端最多发出后端接受请求数的两倍
+	K float64
+	// Window 统计请求数和接受数的时间窗口，按秒分桶
+	Window time.Duration
+	// RejectCodes 视为后端拒绝（过载）的错误码，其他结果都视为后端接受了请求
+	RejectCodes []codes.Code
+}
+
+// DefaultAdaptiveThrottleConfig 返回默认配置
+func DefaultAdaptiveThrottleConfig() *AdaptiveThrottleConfig {
+	return &AdaptiveThrottleConfig{
+		K:           2,
+		Window:      2 * time.Minute,
+		RejectCodes: []codes.Code{codes.ResourceExhausted, codes.Unavailable},
+	}
+}
+
+// AdaptiveThrottle 客户端自适应限流
+// 按目标地址统计时间窗口内的请求数 requests 和后端接受数 accepts，
+// 以 max(0, (requests - K*accepts) / (requests + 1)) 的概率在本地直接拒绝请求，
+// 后端过载时在它自身的限流生效之前就减少发往它的流量
+// 本地拒绝返回不携带 RetryInfo 的 ResourceExhausted，默认的重试策略不会重试，避免重试抵消限流效果
+type AdaptiveThrottle struct {
+	config *AdaptiveThrottleConfig
+
+	mu      sync.Mutex
+	targets map[string]*throttleWindow
+}
+
+// NewAdaptiveThrottle 创建客户端自适应限流
+func NewAdaptiveThrottle(config *AdaptiveThrottleConfig) *AdaptiveThrottle {
+	if config == nil {
+		config = DefaultAdaptiveThrottleConfig()
+	}
+	// 在副本上补全默认值，不修改调用方传入的配置
+	copied := *config
+	config = &copied
+	if config.K < 1 {
+		config.K = 2
+	}
+	if config.Window < time.Second {
+		config.Window = 2 * time.Minute
+	}
+	return &AdaptiveThrottle{
+		config:  config,
+		targets: make(map[string]*throttleWindow),
+	}
+}
+
+// UnaryClientInterceptor 自适应限流拦截器
+func (t *AdaptiveThrottle) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		w := t.window(cc.Target())
+		if err := w.admit(cc.Target()); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		w.record(t.accepted(err))
+		return err
+	}
+}
+
+// StreamClientInterceptor 流式请求自适应限流拦截器，只统计建流的结果
+func (t *AdaptiveThrottle) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		w := t.window(cc.Target())
+		if err := w.admit(cc.Target()); err != nil {
+			return nil, err
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		w.record(t.accepted(err))
+		return cs, err
+	}
+}
+
+// Stats 返回每个目标地址窗口内的请求数、接受数、当前拒绝概率和本地拒绝次数
+func (t *AdaptiveThrottle) Stats() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now().Unix()
+	stats := make(map[string]interface{}, len(t.targets))
+	for target, w := range t.targets {
+		w.mu.Lock()
+		requests, accepts := w.sumLocked(now)
+		stats[target] = map[string]interface{}{
+			"requests":          requests,
+			"accepts":           accepts,
+			"reject_ratio":      w.rejectRatio(requests, accepts),
+			"throttled_locally": w.throttled,
+		}
+		w.mu.Unlock()
+	}
+	return stats
+}
+
+// window 获取目标地址的统计窗口，不存在时创建
+func (t *AdaptiveThrottle) window(target string) *throttleWindow {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w, ok := t.targets[target]
+	if !ok {
+		w = &throttleWindow{
+			k:       t.config.K,
+			buckets: make([]throttleBucket, int(t.config.Window/time.Second)),
+		}
+		t.targets[target] = w
+	}
+	return w
+}
+
+// accepted 后端是否接受了请求
+func (t *AdaptiveThrottle) accepted(err error) bool {
+	if err == nil {
+		return true
+	}
+	return !containsCode(t.config.RejectCodes, status.Code(err))
+}
+
+// throttleWindow 单个目标地址的统计窗口
+type throttleWindow struct {
+	k float64
+
+	mu        sync.Mutex
+	buckets   []throttleBucket
+	throttled int64
+}
+
+// throttleBucket 一秒内的请求数和接受数
+type throttleBucket struct {
+	second   int64
+	requests int64
+	accepts  int64
+}
+
+// admit 按当前拒绝概率决定是否发出请求，本地拒绝的请求同样计入请求数
+func (w *throttleWindow) admit(target string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now().Unix()
+	requests, accepts := w.sumLocked(now)
+	w.bucketLocked(now).requests++
+	if rand.Float64() < w.rejectRatio(requests, accepts) {
+		w.throttled++
+		return status.Errorf(codes.ResourceExhausted, "request throttled by client: target=%s is overloaded", target)
+	}
+	return nil
+}
+
+// record 记录后端是否接受了请求
+func (w *throttleWindow) record(accepted bool) {
+	if !accepted {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.bucketLocked(time.Now().Unix()).accepts++
+}
+
+// rejectRatio 本地拒绝的概率
+func (w *throttleWindow) rejectRatio(requests, accepts int64) float64 {
+	return math.Max(0, (float64(requests)-w.k*float64(accepts))/float64(requests+1))
+}
+
+// bucketLocked 返回当前秒对应的桶，过期的桶被清零
+func (w *throttleWindow) bucketLocked(now int64) *throttleBucket {
+	bucket := &w.buckets[now%int64(len(w.buckets))]
+	if bucket.second != now {
+		*bucket = throttleBucket{second: now}
+	}
+	return bucket
+}
+
+// sumLocked 统计时间窗口内的请求数和接受数
+func (w *throttleWindow) sumLocked(now int64) (requests, accepts int64) {
+	window := int64(len(w.buckets))
+	for _, bucket := range w.buckets {
+		if now-bucket.second < window {
+			requests += bucket.requests
+			accepts += bucket.accepts
+		}
+	}
+	return requests, accepts
+}
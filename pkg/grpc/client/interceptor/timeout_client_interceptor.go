@@ -20,9 +20,14 @@ package interceptor
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // 客户端拦截器
@@ -35,3 +40,123 @@ func TimeoutClientInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor
 		return invoker(ctx, method, req, reply, cc, opts...)
 	}
 }
+
+// TimeoutRule 方法的超时规则，各项为0表示不限制
+type TimeoutRule struct {
+	Methods     []string      // 适用的方法，支持通配符
+	Timeout     time.Duration // 一元请求的超时；流式请求的总时长上限
+	IdleTimeout time.Duration // 仅用于流式请求：等待下一条响应消息的最长时间
+}
+
+// TimeoutConfig 按方法配置的超时
+type TimeoutConfig struct {
+	Rules   []TimeoutRule // 按顺序匹配，使用第一个匹配的规则
+	Default TimeoutRule   // 没有匹配的规则时使用，Methods 字段被忽略
+}
+
+// timeoutTable 方法到超时规则的映射
+type timeoutTable struct {
+	config  *TimeoutConfig
+	methods sync.Map
+}
+
+// rule 查找方法对应的超时规则
+func (t *timeoutTable) rule(method string) *TimeoutRule {
+	if v, ok := t.methods.Load(method); ok {
+		return v.(*TimeoutRule)
+	}
+
+	rule := &t.config.Default
+	for i := range t.config.Rules {
+		if attributes.MatchAny(t.config.Rules[i].Methods, method) {
+			rule = &t.config.Rules[i]
+			break
+		}
+	}
+	t.methods.Store(method, rule)
+	return rule
+}
+
+// MethodTimeoutClientInterceptor 按方法设置超时的拦截器
+// 调用方已设置更早的截止时间时保留调用方的截止时间
+func MethodTimeoutClientInterceptor(config *TimeoutConfig) grpc.UnaryClientInterceptor {
+	table := &timeoutTable{config: config}
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		rule := table.rule(method)
+		if rule.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, rule.Timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamTimeoutClientInterceptor 流式请求超时拦截器
+// Timeout 限制整个流的时长，IdleTimeout 限制每次 RecvMsg 等待下一条消息的时间，
+// 超过 IdleTimeout 时取消流并返回 DeadlineExceeded；调用方处理消息的时间不计入空闲时间
+// 流以任何方式结束（包括客户端流 CloseAndRecv 成功、SendMsg 或 Header 出错）时释放上下文和空闲计时器
+func StreamTimeoutClientInterceptor(config *TimeoutConfig) grpc.StreamClientInterceptor {
+	table := &timeoutTable{config: config}
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		rule := table.rule(method)
+		if rule.Timeout <= 0 && rule.IdleTimeout <= 0 {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		var cancel context.CancelFunc
+		if rule.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, rule.Timeout)
+		} else {
+			ctx, cancel = context.WithCancel(ctx)
+		}
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		s := &timeoutClientStream{ClientStream: cs, idleTimeout: rule.IdleTimeout}
+		if rule.IdleTimeout > 0 {
+			s.idle = time.AfterFunc(rule.IdleTimeout, func() {
+				s.idleExpired.Store(true)
+				cancel()
+			})
+			s.idle.Stop()
+		}
+		// gRPC 在流结束时取消流的上下文，此时释放本拦截器创建的上下文
+		context.AfterFunc(cs.Context(), func() {
+			if s.idle != nil {
+				s.idle.Stop()
+			}
+			cancel()
+		})
+		return s, nil
+	}
+}
+
+// timeoutClientStream 支持空闲超时的 ClientStream
+type timeoutClientStream struct {
+	grpc.ClientStream
+	idleTimeout time.Duration
+	idle        *time.Timer
+	idleExpired atomic.Bool
+}
+
+// RecvMsg 接收消息，等待时间超过空闲超时时取消流
+func (s *timeoutClientStream) RecvMsg(m interface{}) error {
+	if s.idle != nil {
+		s.idle.Reset(s.idleTimeout)
+	}
+	err := s.ClientStream.RecvMsg(m)
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	if err == nil {
+		return nil
+	}
+	if s.idleExpired.Load() {
+		return status.Errorf(codes.DeadlineExceeded, "stream idle timeout: no message received within %s", s.idleTimeout)
+	}
+	return err
+}
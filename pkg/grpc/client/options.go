@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 )
 
@@ -61,6 +62,15 @@ type ClientOptions struct {
 	// 连接池配置
 	Pool *PoolOptions
 
+	// 调用凭证
+	PerRPCCredentials credentials.PerRPCCredentials // 每次调用携带的认证信息
+
+	// 熔断器
+	CircuitBreakers *CircuitBreakerRegistry // 按 (目标地址, 方法) 熔断，为 nil 时不启用
+
+	// 连接异常检测
+	OutlierDetection *OutlierDetectionConfig // 摘除持续出错或响应慢的连接，为 nil 时不启用
+
 	// 通用配置
 	KeepAlive          *keepalive.ClientParameters    // 保活配置
 	UnaryInterceptors  []grpc.UnaryClientInterceptor  // 一元拦截器
@@ -107,6 +117,34 @@ func WithInsecure() ClientOption {
 	}
 }
 
+// WithPerRPCCredentials 设置调用凭证
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) ClientOption {
+	return func(o *ClientOptions) {
+		o.PerRPCCredentials = creds
+	}
+}
+
+// WithTokenSource 使用令牌来源作为调用凭证，令牌只会通过安全连接发送
+func WithTokenSource(source TokenSource) ClientOption {
+	return func(o *ClientOptions) {
+		o.PerRPCCredentials = NewTokenCredentials(source, false)
+	}
+}
+
+// WithCircuitBreaker 启用熔断器，熔断拦截器位于拦截器链的最内层，重试等拦截器的每次尝试都会被统计
+func WithCircuitBreaker(breakers *CircuitBreakerRegistry) ClientOption {
+	return func(o *ClientOptions) {
+		o.CircuitBreakers = breakers
+	}
+}
+
+// WithOutlierDetection 启用连接异常检测
+func WithOutlierDetection(config *OutlierDetectionConfig) ClientOption {
+	return func(o *ClientOptions) {
+		o.OutlierDetection = config
+	}
+}
+
 // WithKeepAlive 设置保活配置
 func WithKeepAlive(config *keepalive.ClientParameters) ClientOption {
 	return func(o *ClientOptions) {
@@ -0,0 +1,191 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package client
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OutlierDetectionConfig 连接异常检测配置
+// 连接处于 Ready 状态但持续返回错误或响应很慢时，将其临时摘除，摘除期间 GetConn 不会选择该连接
+type OutlierDetectionConfig struct {
+	Interval           time.Duration // 检测周期，每个周期统计一次各连接的错误率和平均延迟
+	MinRequests        int64         // 周期内请求数达到此值的连接才参与检测
+	FailureRatio       float64       // 周期内错误率达到此值时摘除，为0时不按错误率检测
+	SlowLatency        time.Duration // 周期内平均延迟超过此值时摘除，为0时不按延迟检测
+	BaseEjectionTime   time.Duration // 首次摘除的时长，连续被摘除时按2的幂增长
+	MaxEjectionTime    time.Duration // 摘除时长的上限
+	MaxEjectionPercent int           // 同一地址、同一类型的连接中最多被摘除的比例（百分比），大于0时至少允许摘除一个
+	FailureCodes       []codes.Code  // 计为失败的错误码
+}
+
+// DefaultOutlierDetectionConfig 返回默认配置
+func DefaultOutlierDetectionConfig() *OutlierDetectionConfig {
+	return &OutlierDetectionConfig{
+		Interval:           10 * time.Second,
+		MinRequests:        10,
+		FailureRatio:       0.5,
+		BaseEjectionTime:   30 * time.Second,
+		MaxEjectionTime:    5 * time.Minute,
+		MaxEjectionPercent: 50,
+		FailureCodes: []codes.Code{
+			codes.Unavailable,
+			codes.DeadlineExceeded,
+			codes.Internal,
+			codes.Unknown,
+		},
+	}
+}
+
+// outlierLoop 定期检测异常连接
+func (p *ConnPool) outlierLoop() {
+	ticker := time.NewTicker(p.outlier.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.RLock()
+			now := time.Now()
+			for _, pool := range p.pools {
+				pool.mu.Lock()
+				p.detectOutliers(pool.address, pool.unaryConns, now)
+				p.detectOutliers(pool.address, pool.streamConns, now)
+				pool.mu.Unlock()
+			}
+			p.mu.RUnlock()
+		case <-p.stopChan:
+			return
+		}
+	}
+}
+
+// detectOutliers 统计上一个周期的结果并摘除异常连接，调用方需持有地址连接池的锁
+func (p *ConnPool) detectOutliers(address string, conns []*ConnInfo, now time.Time) {
+	config := p.outlier
+
+	ejected := 0
+	for _, c := range conns {
+		if c.ejected(now) {
+			ejected++
+		}
+	}
+
+	for _, c := range conns {
+		calls := c.calls.Swap(0)
+		failures := c.failures.Swap(0)
+		latency := time.Duration(c.latency.Swap(0))
+		if c.ejected(now) || calls == 0 || calls < config.MinRequests {
+			continue
+		}
+
+		// FailureRatio 为0时不按错误率检测，否则所有连接都会被判定为异常
+		outlier := (config.FailureRatio > 0 && float64(failures) >= config.FailureRatio*float64(calls)) ||
+			(config.SlowLatency > 0 && latency/time.Duration(calls) > config.SlowLatency)
+		if !outlier {
+			// 表现正常的周期逐步降低下次摘除的时长
+			if c.ejections > 0 {
+				c.ejections--
+			}
+			continue
+		}
+		// 与 gRPC 的异常检测一致，已摘除的比例低于上限时允许再摘除一个，连接数较少时也至少能摘除一个
+		if ejected*100 >= config.MaxEjectionPercent*len(conns) {
+			continue
+		}
+
+		duration := config.BaseEjectionTime << c.ejections
+		if duration <= 0 || (config.MaxEjectionTime > 0 && duration > config.MaxEjectionTime) {
+			duration = config.MaxEjectionTime
+		}
+		c.ejectedUntil.Store(now.Add(duration).UnixNano())
+		c.ejections++
+		ejected++
+		log.Printf("Outlier connection ejected: address=%s, stream=%v, calls=%d, failures=%d, avg_latency=%s, duration=%s",
+			address, c.isStream, calls, failures, latency/time.Duration(calls), duration)
+	}
+}
+
+// outlierUnaryInterceptor 记录每个连接的请求结果，用于异常检测
+func (p *ConnPool) outlierUnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		p.recordOutcome(cc, time.Since(start), err)
+		return err
+	}
+}
+
+// outlierStreamInterceptor 记录每个连接的建流结果，用于异常检测
+func (p *ConnPool) outlierStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		p.recordOutcome(cc, time.Since(start), err)
+		return cs, err
+	}
+}
+
+// recordOutcome 记录一次请求的结果
+func (p *ConnPool) recordOutcome(cc *grpc.ClientConn, elapsed time.Duration, err error) {
+	c := p.connInfo(cc)
+	if c == nil {
+		return
+	}
+	c.calls.Add(1)
+	c.latency.Add(int64(elapsed))
+	if err != nil {
+		code := status.Code(err)
+		for _, fc := range p.outlier.FailureCodes {
+			if fc == code {
+				c.failures.Add(1)
+				break
+			}
+		}
+	}
+}
+
+// connInfo 查找连接对应的 ConnInfo，连接池中的连接以地址作为 Target 创建
+func (p *ConnPool) connInfo(cc *grpc.ClientConn) *ConnInfo {
+	p.mu.RLock()
+	pool, ok := p.pools[cc.Target()]
+	p.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	for _, c := range pool.unaryConns {
+		if c.conn == cc {
+			return c
+		}
+	}
+	for _, c := range pool.streamConns {
+		if c.conn == cc {
+			return c
+		}
+	}
+	return nil
+}
@@ -45,6 +45,8 @@ type ConnPool struct {
 	config   *PoolConfig             // 连接池配置
 	cleanup  *time.Ticker            // 清理定时器
 	stopChan chan struct{}           // 停止信号通道
+	breakers *CircuitBreakerRegistry // 熔断器，由客户端设置，用于统计
+	outlier  *OutlierDetectionConfig // 补全默认值后的异常检测配置副本，为 nil 时不启用
 }
 
 // AddressPool 管理单个地址的连接池。
@@ -65,6 +67,18 @@ type ConnInfo struct {
 	state     connectivity.State // 连接状态
 	load      atomic.Int32       // 当前负载（原子操作）
 	isStream  bool               // 是否是流式连接
+
+	// 异常检测，calls、failures、latency 为当前检测周期内的统计，每个周期清零
+	calls        atomic.Int64 // 请求数
+	failures     atomic.Int64 // 失败数
+	latency      atomic.Int64 // 总延迟（纳秒）
+	ejectedUntil atomic.Int64 // 摘除截止时间（UnixNano），为0表示未被摘除
+	ejections    int          // 连续被摘除的次数，决定下次摘除的时长，由地址连接池的锁保护
+}
+
+// ejected 连接当前是否被摘除
+func (c *ConnInfo) ejected(now time.Time) bool {
+	return c.ejectedUntil.Load() > now.UnixNano()
 }
 
 // PoolConfig 定义连接池的配置参数。
@@ -80,6 +94,9 @@ type PoolConfig struct {
 	ConnMaxLifetime time.Duration // 连接最大生命周期，超过此时间的空闲连接将被清理
 	ConnMaxIdleTime time.Duration // 连接最大空闲时间，超过此时间的空闲连接将被清理
 	DialTimeout     time.Duration // 连接超时时间
+
+	// 异常检测，为 nil 时不启用
+	OutlierDetection *OutlierDetectionConfig
 }
 
 // DefaultPoolConfig 返回默认配置
@@ -112,6 +129,15 @@ func NewConnPool(config *PoolConfig) *ConnPool {
 	}
 
 	go pool.cleanupLoop()
+	if config.OutlierDetection != nil {
+		// 在副本上补全默认值，不修改调用方传入的配置
+		outlier := *config.OutlierDetection
+		if outlier.Interval <= 0 {
+			outlier.Interval = 10 * time.Second
+		}
+		pool.outlier = &outlier
+		go pool.outlierLoop()
+	}
 	return pool
 }
 
@@ -141,10 +167,11 @@ func (p *ConnPool) GetConn(address string, isStream bool, opts ...grpc.DialOptio
 		conns = pool.streamConns
 	}
 
-	// 1. 使用一致性哈希或简单的负载均衡算法来选择连接
+	// 1. 使用一致性哈希或简单的负载均衡算法来选择连接，跳过被异常检测摘除的连接
 	if len(conns) > 0 {
 		// 使用时间戳作为随机种子，确保分布均匀
-		seed := time.Now().UnixNano()
+		now := time.Now()
+		seed := now.UnixNano()
 		startIndex := int(seed % int64(len(conns)))
 
 		// 从随机位置开始遍历，遍历一圈
@@ -152,7 +179,7 @@ func (p *ConnPool) GetConn(address string, isStream bool, opts ...grpc.DialOptio
 			index := (startIndex + i) % len(conns)
 			connInfo := conns[index]
 
-			if connInfo.conn.GetState() == connectivity.Ready {
+			if connInfo.conn.GetState() == connectivity.Ready && !connInfo.ejected(now) {
 				currentLoad := connInfo.load.Load()
 				if currentLoad < p.config.MaxLoadPerConn {
 					connInfo.lastUsed = time.Now()
@@ -301,6 +328,7 @@ func (p *ConnPool) cleanupLoop() {
 // - 空闲连接数（load=0）
 // - 异常连接数
 // - 总负载
+// - 被异常检测摘除的连接数
 // 统计数据按一元调用和流式调用分别统计，并提供每个地址的详细统计。
 func (p *ConnPool) Stats() map[string]interface{} {
 	p.mu.RLock()
@@ -310,21 +338,23 @@ func (p *ConnPool) Stats() map[string]interface{} {
 		"unary": map[string]interface{}{
 			"by_address": make(map[string]interface{}),
 			"total": map[string]interface{}{
-				"total_connections":  0,
-				"ready_connections":  0,        // Ready状态的连接数
-				"idle_connections":   0,        // 空闲连接数（load=0）
-				"failed_connections": 0,        // 失败的连接数（TransientFailure或Shutdown）
-				"total_load":         int32(0), // 总负载
+				"total_connections":   0,
+				"ready_connections":   0,        // Ready状态的连接数
+				"idle_connections":    0,        // 空闲连接数（load=0）
+				"failed_connections":  0,        // 失败的连接数（TransientFailure或Shutdown）
+				"ejected_connections": 0,        // 被异常检测摘除的连接数
+				"total_load":          int32(0), // 总负载
 			},
 		},
 		"stream": map[string]interface{}{
 			"by_address": make(map[string]interface{}),
 			"total": map[string]interface{}{
-				"total_connections":  0,
-				"ready_connections":  0,
-				"idle_connections":   0,
-				"failed_connections": 0,
-				"total_load":         int32(0),
+				"total_connections":   0,
+				"ready_connections":   0,
+				"idle_connections":    0,
+				"failed_connections":  0,
+				"ejected_connections": 0,
+				"total_load":          int32(0),
 			},
 		},
 	}
@@ -332,16 +362,18 @@ func (p *ConnPool) Stats() map[string]interface{} {
 	unaryStats := stats["unary"].(map[string]interface{})
 	streamStats := stats["stream"].(map[string]interface{})
 
+	now := time.Now()
 	for addr, pool := range p.pools {
 		pool.mu.RLock()
 
 		// 统计一元连接
 		unaryAddrStats := map[string]interface{}{
-			"total_connections":  len(pool.unaryConns),
-			"ready_connections":  0,
-			"idle_connections":   0,
-			"failed_connections": 0,
-			"total_load":         int32(0),
+			"total_connections":   len(pool.unaryConns),
+			"ready_connections":   0,
+			"idle_connections":    0,
+			"failed_connections":  0,
+			"ejected_connections": 0,
+			"total_load":          int32(0),
 		}
 
 		// 统计每个一元连接的状态
@@ -358,6 +390,9 @@ func (p *ConnPool) Stats() map[string]interface{} {
 			case connectivity.TransientFailure, connectivity.Shutdown:
 				unaryAddrStats["failed_connections"] = unaryAddrStats["failed_connections"].(int) + 1
 			}
+			if connInfo.ejected(now) {
+				unaryAddrStats["ejected_connections"] = unaryAddrStats["ejected_connections"].(int) + 1
+			}
 			unaryAddrStats["total_load"] = unaryAddrStats["total_load"].(int32) + load
 		}
 
@@ -367,17 +402,19 @@ func (p *ConnPool) Stats() map[string]interface{} {
 		unaryTotal["ready_connections"] = unaryTotal["ready_connections"].(int) + unaryAddrStats["ready_connections"].(int)
 		unaryTotal["idle_connections"] = unaryTotal["idle_connections"].(int) + unaryAddrStats["idle_connections"].(int)
 		unaryTotal["failed_connections"] = unaryTotal["failed_connections"].(int) + unaryAddrStats["failed_connections"].(int)
+		unaryTotal["ejected_connections"] = unaryTotal["ejected_connections"].(int) + unaryAddrStats["ejected_connections"].(int)
 		unaryTotal["total_load"] = unaryTotal["total_load"].(int32) + unaryAddrStats["total_load"].(int32)
 
 		unaryStats["by_address"].(map[string]interface{})[addr] = unaryAddrStats
 
 		// 统计流式连接（逻辑相同）
 		streamAddrStats := map[string]interface{}{
-			"total_connections":  len(pool.streamConns),
-			"ready_connections":  0,
-			"idle_connections":   0,
-			"failed_connections": 0,
-			"total_load":         int32(0),
+			"total_connections":   len(pool.streamConns),
+			"ready_connections":   0,
+			"idle_connections":    0,
+			"failed_connections":  0,
+			"ejected_connections": 0,
+			"total_load":          int32(0),
 		}
 
 		for _, connInfo := range pool.streamConns {
@@ -393,6 +430,9 @@ func (p *ConnPool) Stats() map[string]interface{} {
 			case connectivity.TransientFailure, connectivity.Shutdown:
 				streamAddrStats["failed_connections"] = streamAddrStats["failed_connections"].(int) + 1
 			}
+			if connInfo.ejected(now) {
+				streamAddrStats["ejected_connections"] = streamAddrStats["ejected_connections"].(int) + 1
+			}
 			streamAddrStats["total_load"] = streamAddrStats["total_load"].(int32) + load
 		}
 
@@ -402,6 +442,7 @@ func (p *ConnPool) Stats() map[string]interface{} {
 		streamTotal["ready_connections"] = streamTotal["ready_connections"].(int) + streamAddrStats["ready_connections"].(int)
 		streamTotal["idle_connections"] = streamTotal["idle_connections"].(int) + streamAddrStats["idle_connections"].(int)
 		streamTotal["failed_connections"] = streamTotal["failed_connections"].(int) + streamAddrStats["failed_connections"].(int)
+		streamTotal["ejected_connections"] = streamTotal["ejected_connections"].(int) + streamAddrStats["ejected_connections"].(int)
 		streamTotal["total_load"] = streamTotal["total_load"].(int32) + streamAddrStats["total_load"].(int32)
 
 		streamStats["by_address"].(map[string]interface{})[addr] = streamAddrStats
@@ -409,6 +450,11 @@ func (p *ConnPool) Stats() map[string]interface{} {
 		pool.mu.RUnlock()
 	}
 
+	// 熔断器状态
+	if p.breakers != nil {
+		stats["circuit_breakers"] = p.breakers.Stats()
+	}
+
 	return stats
 }
 
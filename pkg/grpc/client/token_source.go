@@ -0,0 +1,330 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// Token 访问令牌
+type Token struct {
+	AccessToken string    // 令牌内容
+	TokenType   string    // 令牌类型，为空时使用 Bearer
+	Expiry      time.Time // 过期时间，零值表示永不过期
+}
+
+// Type 返回令牌类型
+func (t *Token) Type() string {
+	if t.TokenType == "" {
+		return "Bearer"
+	}
+	return t.TokenType
+}
+
+// expired 令牌在 now 时刻是否已过期
+func (t *Token) expired(now time.Time) bool {
+	return !t.Expiry.IsZero() && !now.Before(t.Expiry)
+}
+
+// TokenSource 令牌来源接口
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// CachedTokenSource 缓存令牌并在过期前主动刷新
+// 令牌进入刷新窗口（过期前 RefreshBefore）后，仍然返回当前令牌，同时在后台刷新；
+// 令牌已过期时同步获取新令牌，并发调用只会触发一次获取
+type CachedTokenSource struct {
+	source        TokenSource
+	refreshBefore time.Duration
+	fetchTimeout  time.Duration
+
+	mu         sync.Mutex
+	token      *Token
+	refreshing bool
+
+	fetchMu sync.Mutex // 保证同一时刻只有一个获取请求
+}
+
+// NewCachedTokenSource 创建带缓存的令牌来源，refreshBefore 为过期前主动刷新的提前量
+func NewCachedTokenSource(source TokenSource, refreshBefore time.Duration) *CachedTokenSource {
+	return &CachedTokenSource{
+		source:        source,
+		refreshBefore: refreshBefore,
+		fetchTimeout:  30 * time.Second,
+	}
+}
+
+// Token 返回缓存的令牌，必要时刷新
+func (s *CachedTokenSource) Token(ctx context.Context) (*Token, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	tok := s.token
+	if tok != nil && !tok.expired(now) {
+		if !tok.Expiry.IsZero() && !now.Before(tok.Expiry.Add(-s.refreshBefore)) && !s.refreshing {
+			s.refreshing = true
+			go s.refreshInBackground()
+		}
+		s.mu.Unlock()
+		return tok, nil
+	}
+	s.mu.Unlock()
+
+	return s.fetch(ctx)
+}
+
+// fetch 同步获取令牌
+func (s *CachedTokenSource) fetch(ctx context.Context) (*Token, error) {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	// 等待锁期间可能已经有其他调用获取到新令牌
+	s.mu.Lock()
+	tok := s.token
+	s.mu.Unlock()
+	if tok != nil && !tok.expired(time.Now()) {
+		return tok, nil
+	}
+
+	tok, err := s.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.token = tok
+	s.mu.Unlock()
+	return tok, nil
+}
+
+// refreshInBackground 后台刷新令牌，失败时保留旧令牌直到过期
+func (s *CachedTokenSource) refreshInBackground() {
+	defer func() {
+		s.mu.Lock()
+		s.refreshing = false
+		s.mu.Unlock()
+	}()
+
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.fetchTimeout)
+	defer cancel()
+
+	tok, err := s.source.Token(ctx)
+	if err != nil {
+		log.Printf("token source: background refresh failed: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.token = tok
+	s.mu.Unlock()
+}
+
+// ClientCredentialsConfig OAuth2 client credentials 模式配置
+type ClientCredentialsConfig struct {
+	TokenURL       string       // 令牌端点
+	ClientID       string       // 客户端ID
+	ClientSecret   string       // 客户端密钥
+	Scopes         []string     // 申请的权限范围
+	EndpointParams url.Values   // 额外的请求参数，例如 audience
+	AuthInParams   bool         // 为 true 时在请求体中发送客户端凭证，否则使用 HTTP Basic 认证
+	HTTPClient     *http.Client // HTTP 客户端，为空时使用带超时的默认客户端
+}
+
+// clientCredentialsSource 从令牌端点获取令牌
+type clientCredentialsSource struct {
+	config *ClientCredentialsConfig
+	client *http.Client
+}
+
+// NewClientCredentialsTokenSource 创建 OAuth2 client credentials 令牌来源
+// 返回的令牌来源带缓存，并在过期前 refreshBefore 主动刷新
+func NewClientCredentialsTokenSource(config *ClientCredentialsConfig, refreshBefore time.Duration) *CachedTokenSource {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return NewCachedTokenSource(&clientCredentialsSource{
+		config: config,
+		client: httpClient,
+	}, refreshBefore)
+}
+
+// tokenResponse 令牌端点的响应
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// Token 请求令牌端点
+func (s *clientCredentialsSource) Token(ctx context.Context) (*Token, error) {
+	form := url.Values{}
+	for k, v := range s.config.EndpointParams {
+		form[k] = v
+	}
+	form.Set("grant_type", "client_credentials")
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	if s.config.AuthInParams {
+		form.Set("client_id", s.config.ClientID)
+		form.Set("client_secret", s.config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !s.config.AuthInParams {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %v", err)
+	}
+
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, fmt.Errorf("failed to parse token response: status=%d, err=%v", resp.StatusCode, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || tr.Error != "" {
+		return nil, fmt.Errorf("token endpoint error: status=%d, error=%s, description=%s", resp.StatusCode, tr.Error, tr.ErrorDescription)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned empty access_token")
+	}
+
+	tok := &Token{
+		AccessToken: tr.AccessToken,
+		TokenType:   tr.TokenType,
+	}
+	if tr.ExpiresIn != "" {
+		seconds, err := tr.ExpiresIn.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_in: %v", err)
+		}
+		if seconds > 0 {
+			// 以发起请求的时间为基准计算过期时间，留出网络耗时的余量
+			tok.Expiry = start.Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	return tok, nil
+}
+
+// fileTokenSource 从文件读取令牌，例如 Kubernetes projected service account token
+type fileTokenSource struct {
+	path     string
+	interval time.Duration
+}
+
+// NewFileTokenSource 创建基于文件的令牌来源，每隔 interval 在后台重新读取一次文件
+// 读取失败时（例如 kubelet 替换令牌文件的瞬间）继续使用上一次读到的令牌，最多再使用 interval
+func NewFileTokenSource(path string, interval time.Duration) *CachedTokenSource {
+	return NewCachedTokenSource(&fileTokenSource{
+		path:     path,
+		interval: interval,
+	}, interval)
+}
+
+// Token 读取文件中的令牌
+func (s *fileTokenSource) Token(ctx context.Context) (*Token, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %v", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return nil, fmt.Errorf("token file %s is empty", s.path)
+	}
+
+	// 有效期为两个读取周期：第一个周期结束后进入刷新窗口在后台重新读取，
+	// 第二个周期作为读取失败时继续使用旧令牌的余量
+	tok := &Token{AccessToken: token}
+	if s.interval > 0 {
+		tok.Expiry = time.Now().Add(2 * s.interval)
+	}
+	return tok, nil
+}
+
+// TokenCredentials 将 TokenSource 适配为 gRPC 的 PerRPCCredentials
+// 默认要求传输层安全，使用非安全连接时拒绝发送令牌，除非显式允许
+type TokenCredentials struct {
+	source        TokenSource
+	allowInsecure bool
+}
+
+// NewTokenCredentials 创建基于令牌来源的调用凭证
+func NewTokenCredentials(source TokenSource, allowInsecure bool) *TokenCredentials {
+	return &TokenCredentials{
+		source:        source,
+		allowInsecure: allowInsecure,
+	}
+}
+
+// GetRequestMetadata 获取请求需要携带的认证信息
+func (c *TokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if !c.allowInsecure {
+		ri, _ := credentials.RequestInfoFromContext(ctx)
+		if err := credentials.CheckSecurityLevel(ri.AuthInfo, credentials.PrivacyAndIntegrity); err != nil {
+			return nil, fmt.Errorf("refusing to send token over insecure connection: %v", err)
+		}
+	}
+
+	tok, err := c.source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{
+		"authorization": tok.Type() + " " + tok.AccessToken,
+	}, nil
+}
+
+// RequireTransportSecurity 是否要求传输层安全
+func (c *TokenCredentials) RequireTransportSecurity() bool {
+	return !c.allowInsecure
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tokenEndpoint 测试用令牌端点，每次请求返回递增的令牌
+type tokenEndpoint struct {
+	server   *httptest.Server
+	requests atomic.Int64
+
+	status    int
+	body      string // 非空时原样返回，否则返回递增的令牌
+	expiresIn string
+	delay     time.Duration
+}
+
+func newTokenEndpoint(t *testing.T, e *tokenEndpoint) *tokenEndpoint {
+	t.Helper()
+	e.server = httptest.NewServer(http.HandlerFunc(e.handle))
+	t.Cleanup(e.server.Close)
+	return e
+}
+
+func (e *tokenEndpoint) handle(w http.ResponseWriter, r *http.Request) {
+	n := e.requests.Add(1)
+	if e.delay > 0 {
+		time.Sleep(e.delay)
+	}
+
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"unsupported_grant_type"}`)
+		return
+	}
+	if id, secret, ok := r.BasicAuth(); !ok || id != "svc" || secret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid_client"}`)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if e.status != 0 {
+		w.WriteHeader(e.status)
+	}
+	if e.body != "" {
+		fmt.Fprint(w, e.body)
+		return
+	}
+	if e.expiresIn != "" {
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%s}`, n, e.expiresIn)
+		return
+	}
+	fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer"}`, n)
+}
+
+func (e *tokenEndpoint) config() *ClientCredentialsConfig {
+	return &ClientCredentialsConfig{
+		TokenURL:     e.server.URL,
+		ClientID:     "svc",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	}
+}
+
+func TestClientCredentialsErrorResponse(t *testing.T) {
+	e := newTokenEndpoint(t, &tokenEndpoint{
+		status: http.StatusBadRequest,
+		body:   `{"error":"invalid_scope","error_description":"scope write is not allowed"}`,
+	})
+
+	_, err := NewClientCredentialsTokenSource(e.config(), 0).Token(context.Background())
+	if err == nil {
+		t.Fatal("expected error for non-2xx response")
+	}
+	for _, want := range []string{"status=400", "invalid_scope", "scope write is not allowed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestClientCredentialsExpiresIn(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn string
+		want      time.Duration // 0 表示永不过期
+		wantErr   bool
+	}{
+		{name: "number", expiresIn: "120", want: 120 * time.Second},
+		{name: "string", expiresIn: `"120"`, want: 120 * time.Second},
+		{name: "absent"},
+		{name: "zero", expiresIn: "0"},
+		{name: "invalid", expiresIn: "1.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTokenEndpoint(t, &tokenEndpoint{expiresIn: tt.expiresIn})
+
+			before := time.Now()
+			tok, err := NewClientCredentialsTokenSource(e.config(), 0).Token(context.Background())
+			after := time.Now()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for expires_in=%s", tt.expiresIn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Token() error: %v", err)
+			}
+
+			if tt.want == 0 {
+				if !tok.Expiry.IsZero() {
+					t.Errorf("expected no expiry, got %v", tok.Expiry)
+				}
+				return
+			}
+			if tok.Expiry.Before(before.Add(tt.want)) || tok.Expiry.After(after.Add(tt.want)) {
+				t.Errorf("expiry %v not within [%v, %v]", tok.Expiry, before.Add(tt.want), after.Add(tt.want))
+			}
+		})
+	}
+}
+
+func TestCachedTokenSourceBackgroundRefresh(t *testing.T) {
+	e := newTokenEndpoint(t, &tokenEndpoint{expiresIn: "3600"})
+	// 刷新窗口覆盖整个有效期，获取到的令牌立即进入刷新窗口
+	src := NewClientCredentialsTokenSource(e.config(), time.Hour)
+
+	tok, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error: %v", err)
+	}
+	if tok.AccessToken != "token-1" {
+		t.Fatalf("expected token-1, got %s", tok.AccessToken)
+	}
+
+	// 刷新窗口内仍然返回当前令牌，同时触发后台刷新
+	tok, err = src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error: %v", err)
+	}
+	if tok.AccessToken != "token-1" {
+		t.Fatalf("expected cached token-1 during refresh window, got %s", tok.AccessToken)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		src.mu.Lock()
+		current, refreshing := src.token, src.refreshing
+		src.mu.Unlock()
+		if current.AccessToken == "token-2" && !refreshing {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background refresh did not complete, token=%s", current.AccessToken)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := e.requests.Load(); n != 2 {
+		t.Errorf("expected 2 token requests, got %d", n)
+	}
+}
+
+func TestCachedTokenSourceSingleFetch(t *testing.T) {
+	e := newTokenEndpoint(t, &tokenEndpoint{expiresIn: "3600", delay: 50 * time.Millisecond})
+	src := NewClientCredentialsTokenSource(e.config(), time.Minute)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 16)
+	errs := make([]error, len(tokens))
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tok, err := src.Token(context.Background())
+			if err == nil {
+				tokens[i] = tok.AccessToken
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range tokens {
+		if errs[i] != nil {
+			t.Fatalf("Token() error: %v", errs[i])
+		}
+		if tokens[i] != "token-1" {
+			t.Errorf("caller %d got %s, expected token-1", i, tokens[i])
+		}
+	}
+	if n := e.requests.Load(); n != 1 {
+		t.Errorf("expected 1 token request for concurrent callers, got %d", n)
+	}
+}
+
+func TestTokenCredentialsInsecureConnection(t *testing.T) {
+	e := newTokenEndpoint(t, &tokenEndpoint{expiresIn: "3600"})
+	src := NewClientCredentialsTokenSource(e.config(), 0)
+
+	// 上下文中没有安全连接信息，拒绝发送令牌，也不会请求令牌端点
+	creds := NewTokenCredentials(src, false)
+	if !creds.RequireTransportSecurity() {
+		t.Error("expected RequireTransportSecurity to be true")
+	}
+	if _, err := creds.GetRequestMetadata(context.Background()); err == nil {
+		t.Fatal("expected error when sending token over insecure connection")
+	}
+	if n := e.requests.Load(); n != 0 {
+		t.Errorf("expected no token request, got %d", n)
+	}
+
+	// 显式允许非安全连接时正常发送
+	creds = NewTokenCredentials(src, true)
+	if creds.RequireTransportSecurity() {
+		t.Error("expected RequireTransportSecurity to be false")
+	}
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("GetRequestMetadata() error: %v", err)
+	}
+	if md["authorization"] != "Bearer token-1" {
+		t.Errorf("unexpected authorization %q", md["authorization"])
+	}
+}
+
+func TestFileTokenSourceKeepsLastGoodToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	interval := 100 * time.Millisecond
+	src := NewFileTokenSource(path, interval)
+
+	tok, err := src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error: %v", err)
+	}
+	if tok.AccessToken != "file-token-1" {
+		t.Fatalf("expected file-token-1, got %s", tok.AccessToken)
+	}
+
+	// 读取周期结束后文件暂时不可读，刷新窗口内仍然返回上一次读到的令牌
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(interval + 20*time.Millisecond)
+	tok, err = src.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() should serve the last good token, got error: %v", err)
+	}
+	if tok.AccessToken != "file-token-1" {
+		t.Fatalf("expected file-token-1, got %s", tok.AccessToken)
+	}
+
+	// 文件恢复后由后台刷新读到新令牌
+	if err := os.WriteFile(path, []byte("file-token-2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(interval)
+	for {
+		tok, err = src.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error: %v", err)
+		}
+		if tok.AccessToken == "file-token-2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("background refresh did not pick up the new token, got %s", tok.AccessToken)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
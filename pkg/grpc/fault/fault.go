@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package fault
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// HeaderKey 按需注入故障的 metadata 键，取值形如 "code=UNAVAILABLE,delay=200ms"，code 也可以是数字
+// 只有使用 -tags faultinject 构建且配置启用时才生效，默认构建中该 metadata 被忽略
+const HeaderKey = "x-fault-inject"
+
+// DefaultMaxHeaderDelay 通过 HeaderKey 请求的延迟默认上限
+const DefaultMaxHeaderDelay = 5 * time.Second
+
+// Rule 故障注入规则
+type Rule struct {
+	Name       string        `json:"name"`       // 规则名称，用于统计
+	Methods    []string      `json:"methods"`    // 适用的方法，支持通配符
+	Percentage float64       `json:"percentage"` // 注入比例，取值 [0, 100]
+	Delay      time.Duration `json:"delay"`      // 注入的延迟，为0时不延迟
+	Code       codes.Code    `json:"code"`       // 注入的错误码，为 OK 时只注入延迟
+	Message    string        `json:"message"`    // 错误信息，为空时使用默认信息
+}
+
+// Config 故障注入配置
+type Config struct {
+	Enabled        bool          `json:"enabled"`          // 总开关，关闭时规则和 HeaderKey 都不生效
+	Rules          []Rule        `json:"rules"`            // 按顺序匹配，方法只使用第一个匹配的规则
+	MaxHeaderDelay time.Duration `json:"max_header_delay"` // HeaderKey 请求的延迟上限，为0时使用 DefaultMaxHeaderDelay
+}
+
+// Injector 故障注入器，用于混沌测试
+// 配置可以在运行时通过 Update 整体替换，正在进行的请求不受影响
+type Injector struct {
+	config   atomic.Pointer[Config]
+	injected atomic.Int64
+	delayed  atomic.Int64
+}
+
+// NewInjector 创建故障注入器
+func NewInjector(config *Config) *Injector {
+	f := &Injector{}
+	f.Update(config)
+	return f
+}
+
+// Update 替换故障注入配置
+func (f *Injector) Update(config *Config) {
+	if config == nil {
+		config = &Config{}
+	}
+	f.config.Store(config)
+}
+
+// Config 返回当前的故障注入配置
+func (f *Injector) Config() *Config {
+	return f.config.Load()
+}
+
+// Inject 对本次调用注入故障，返回需要返回给调用方的错误，没有注入错误时返回 nil
+// md 为请求携带的 metadata，faultinject 构建中其中的 HeaderKey 优先于配置的规则
+func (f *Injector) Inject(ctx context.Context, fullMethod string, md metadata.MD) error {
+	config := f.config.Load()
+	if !config.Enabled {
+		return nil
+	}
+
+	if rule, ok := headerFault(md); ok {
+		maxDelay := config.MaxHeaderDelay
+		if maxDelay <= 0 {
+			maxDelay = DefaultMaxHeaderDelay
+		}
+		if rule.Delay > maxDelay {
+			rule.Delay = maxDelay
+		}
+		return f.apply(ctx, fullMethod, rule)
+	}
+
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if !attributes.MatchAny(rule.Methods, fullMethod) {
+			continue
+		}
+		if rand.Float64()*100 >= rule.Percentage {
+			return nil
+		}
+		return f.apply(ctx, fullMethod, rule)
+	}
+	return nil
+}
+
+// Stats 返回注入错误和注入延迟的次数
+func (f *Injector) Stats() map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":  f.config.Load().Enabled,
+		"injected": f.injected.Load(),
+		"delayed":  f.delayed.Load(),
+	}
+}
+
+// apply 执行故障规则
+func (f *Injector) apply(ctx context.Context, fullMethod string, rule *Rule) error {
+	if rule.Delay > 0 {
+		f.delayed.Add(1)
+		timer := time.NewTimer(rule.Delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if rule.Code == codes.OK {
+		return nil
+	}
+
+	f.injected.Add(1)
+	message := rule.Message
+	if message == "" {
+		message = "injected fault: method=" + fullMethod
+	}
+	return status.Error(rule.Code, message)
+}
@@ -0,0 +1,30 @@
+//go:build !faultinject
+
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package fault
+
+import (
+	"google.golang.org/grpc/metadata"
+)
+
+// headerFault 默认构建中忽略请求携带的 HeaderKey，避免外部请求触发故障
+func headerFault(metadata.MD) (*Rule, bool) {
+	return nil, false
+}
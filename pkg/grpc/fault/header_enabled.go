@@ -0,0 +1,72 @@
+//go:build faultinject
+
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package fault
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// headerFault 解析请求携带的 HeaderKey，仅在 faultinject 构建中启用
+func headerFault(md metadata.MD) (*Rule, bool) {
+	values := md.Get(HeaderKey)
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	rule := &Rule{Name: "header", Percentage: 100}
+	for _, field := range strings.Split(values[0], ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(field), "=")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "delay":
+			if d, err := time.ParseDuration(value); err == nil {
+				rule.Delay = d
+			}
+		case "code":
+			if c, ok := parseCode(value); ok {
+				rule.Code = c
+			}
+		}
+	}
+	if rule.Delay <= 0 && rule.Code == codes.OK {
+		return nil, false
+	}
+	return rule, true
+}
+
+// parseCode 解析错误码，支持名称（例如 UNAVAILABLE）和数字
+func parseCode(s string) (codes.Code, bool) {
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return codes.Code(n), n < 17
+	}
+	var c codes.Code
+	if err := c.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(s)))); err != nil {
+		return codes.OK, false
+	}
+	return c, true
+}
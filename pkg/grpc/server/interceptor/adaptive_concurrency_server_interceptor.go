@@ -0,0 +1,249 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConcurrencyAlgorithm 并发上限的调整算法
+type ConcurrencyAlgorithm string
+
+const (
+	// ConcurrencyAIMD 加性增、乘性减：延迟超过阈值或请求超时时按比例缩小上限，否则逐步加1
+	ConcurrencyAIMD ConcurrencyAlgorithm = "aimd"
+	// ConcurrencyGradient 梯度算法：根据长期延迟与短期延迟的比值调整上限，延迟上升时自动收缩
+	ConcurrencyGradient ConcurrencyAlgorithm = "gradient"
+)
+
+// AdaptiveConcurrencyConfig 自适应并发限制配置
+type AdaptiveConcurrencyConfig struct {
+	Algorithm    ConcurrencyAlgorithm // 调整算法
+	InitialLimit int                  // 初始并发上限
+	MinLimit     int                  // 并发上限的下限
+	MaxLimit     int                  // 并发上限的上限
+
+	// AIMD 参数
+	LatencyThreshold time.Duration // 单个请求延迟超过此值视为过载信号
+	BackoffRatio     float64       // 过载时上限乘以的比例，取值 (0, 1)
+
+	// Gradient 参数
+	Smoothing float64 // 新上限的平滑系数，取值 (0, 1]，越小调整越平缓
+	Tolerance float64 // 短期延迟相对长期延迟的容忍倍数，不小于1
+}
+
+// DefaultAdaptiveConcurrencyConfig 返回默认配置
+func DefaultAdaptiveConcurrencyConfig(algorithm ConcurrencyAlgorithm) *AdaptiveConcurrencyConfig {
+	return &AdaptiveConcurrencyConfig{
+		Algorithm:        algorithm,
+		InitialLimit:     20,
+		MinLimit:         1,
+		MaxLimit:         1000,
+		LatencyThreshold: time.Second,
+		BackoffRatio:     0.9,
+		Smoothing:        0.2,
+		Tolerance:        1.5,
+	}
+}
+
+// AdaptiveConcurrencyLimiter 按方法自适应调整并发上限的限流器
+// 并发数达到当前上限时直接拒绝请求（ResourceExhausted），上限根据观测到的延迟动态调整
+type AdaptiveConcurrencyLimiter struct {
+	config *AdaptiveConcurrencyConfig
+
+	mu      sync.RWMutex
+	methods map[string]*methodConcurrency
+}
+
+// NewAdaptiveConcurrencyLimiter 创建自适应并发限制器
+func NewAdaptiveConcurrencyLimiter(config *AdaptiveConcurrencyConfig) *AdaptiveConcurrencyLimiter {
+	if config == nil {
+		config = DefaultAdaptiveConcurrencyConfig(ConcurrencyGradient)
+	}
+	if config.MinLimit <= 0 {
+		config.MinLimit = 1
+	}
+	if config.MaxLimit < config.MinLimit {
+		config.MaxLimit = config.MinLimit
+	}
+	if config.InitialLimit < config.MinLimit || config.InitialLimit > config.MaxLimit {
+		config.InitialLimit = config.MinLimit
+	}
+	if config.BackoffRatio <= 0 || config.BackoffRatio >= 1 {
+		config.BackoffRatio = 0.9
+	}
+	if config.Smoothing <= 0 || config.Smoothing > 1 {
+		config.Smoothing = 0.2
+	}
+	if config.Tolerance < 1 {
+		config.Tolerance = 1.5
+	}
+	return &AdaptiveConcurrencyLimiter{
+		config:  config,
+		methods: make(map[string]*methodConcurrency),
+	}
+}
+
+// UnaryServerInterceptor 自适应并发限制拦截器
+func (l *AdaptiveConcurrencyLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		m := l.method(info.FullMethod)
+		inflight, ok := m.acquire()
+		if !ok {
+			return nil, status.Errorf(codes.ResourceExhausted, "concurrency limit exceeded: method=%s", info.FullMethod)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+		m.release(time.Since(start), inflight, code == codes.DeadlineExceeded || code == codes.ResourceExhausted)
+		return resp, err
+	}
+}
+
+// Stats 返回每个方法当前的并发上限、并发数和拒绝次数
+func (l *AdaptiveConcurrencyLimiter) Stats() map[string]interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := make(map[string]interface{}, len(l.methods))
+	for name, m := range l.methods {
+		m.mu.Lock()
+		stats[name] = map[string]interface{}{
+			"limit":    int(m.limit),
+			"inflight": m.inflight,
+			"rejected": m.rejected,
+		}
+		m.mu.Unlock()
+	}
+	return stats
+}
+
+// method 获取方法对应的限制器，不存在时创建
+func (l *AdaptiveConcurrencyLimiter) method(fullMethod string) *methodConcurrency {
+	l.mu.RLock()
+	m, ok := l.methods[fullMethod]
+	l.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if m, ok = l.methods[fullMethod]; ok {
+		return m
+	}
+	m = &methodConcurrency{
+		config: l.config,
+		limit:  float64(l.config.InitialLimit),
+	}
+	l.methods[fullMethod] = m
+	return m
+}
+
+// methodConcurrency 单个方法的并发限制状态
+type methodConcurrency struct {
+	config *AdaptiveConcurrencyConfig
+
+	mu       sync.Mutex
+	limit    float64
+	inflight int
+	rejected int64
+
+	// 梯度算法使用的延迟统计（纳秒）
+	shortRTT float64
+	longRTT  float64
+}
+
+// acquire 占用一个并发名额，返回占用后的并发数
+func (m *methodConcurrency) acquire() (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inflight >= int(m.limit) {
+		m.rejected++
+		return 0, false
+	}
+	m.inflight++
+	return m.inflight, true
+}
+
+// release 释放并发名额，并根据本次请求的延迟调整上限
+func (m *methodConcurrency) release(rtt time.Duration, inflight int, dropped bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight--
+
+	var limit float64
+	switch m.config.Algorithm {
+	case ConcurrencyAIMD:
+		limit = m.aimd(rtt, inflight, dropped)
+	default:
+		limit = m.gradient(rtt, inflight, dropped)
+	}
+	m.limit = math.Max(float64(m.config.MinLimit), math.Min(float64(m.config.MaxLimit), limit))
+}
+
+// aimd 加性增、乘性减
+func (m *methodConcurrency) aimd(rtt time.Duration, inflight int, dropped bool) float64 {
+	if dropped || (m.config.LatencyThreshold > 0 && rtt > m.config.LatencyThreshold) {
+		return m.limit * m.config.BackoffRatio
+	}
+	// 只有并发数接近上限时才增加，避免空闲时上限无限增长
+	if float64(inflight)*2 >= m.limit {
+		return m.limit + 1
+	}
+	return m.limit
+}
+
+// gradient 梯度算法
+// 长期延迟代表无排队时的基准延迟，短期延迟反映当前的排队情况，
+// 两者的比值小于1时说明请求开始排队，按比例收缩上限；同时预留 sqrt(limit) 的排队余量用于探测更高的上限
+func (m *methodConcurrency) gradient(rtt time.Duration, inflight int, dropped bool) float64 {
+	sample := float64(rtt)
+	if m.longRTT == 0 {
+		m.shortRTT = sample
+		m.longRTT = sample
+		return m.limit
+	}
+	m.shortRTT = m.shortRTT*0.9 + sample*0.1
+	m.longRTT = m.longRTT*0.995 + sample*0.005
+
+	// 负载突降后长期延迟偏高，加速向短期延迟靠拢
+	if m.longRTT > 2*m.shortRTT {
+		m.longRTT *= 0.95
+	}
+
+	if dropped {
+		return m.limit * 0.5
+	}
+	// 并发数远低于上限时延迟不能反映容量，保持不变
+	if float64(inflight) < m.limit/2 {
+		return m.limit
+	}
+
+	g := math.Max(0.5, math.Min(1.0, m.config.Tolerance*m.longRTT/m.shortRTT))
+	newLimit := m.limit*g + math.Sqrt(m.limit)
+	return m.limit*(1-m.config.Smoothing) + newLimit*m.config.Smoothing
+}
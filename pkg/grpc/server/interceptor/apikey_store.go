@@ -0,0 +1,284 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// 密钥校验错误
+var (
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrAPIKeyExpired      = errors.New("api key expired")
+	ErrAPIKeyNotYetValid  = errors.New("api key not yet valid")
+	ErrAPIKeyInvalidEntry = errors.New("invalid api key entry")
+)
+
+// APIKey 描述一个已登记的密钥
+// 存储中只保存密钥的 SHA-256 摘要，不保存明文
+// NotBefore 和 ExpiresAt 为零值时表示不限制，轮换时新旧密钥可以有一段重叠的有效期
+type APIKey struct {
+	Label     string    `json:"label"`      // 密钥标签，例如 billing-2025-06
+	Owner     string    `json:"owner"`      // 密钥所有者，作为调用方身份
+	Hash      string    `json:"hash"`       // 密钥明文的 SHA-256 摘要（十六进制）
+	NotBefore time.Time `json:"not_before"` // 生效时间
+	ExpiresAt time.Time `json:"expires_at"` // 过期时间
+}
+
+// HashAPIKey 计算密钥明文的摘要，用于生成 APIKey.Hash
+func HashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyStore 密钥存储接口
+type KeyStore interface {
+	// Verify 校验密钥明文，成功时返回对应的密钥信息
+	Verify(ctx context.Context, raw string) (*APIKey, error)
+}
+
+// storedKey 预先解析好摘要的密钥
+type storedKey struct {
+	key  APIKey
+	hash [sha256.Size]byte
+}
+
+// MemoryKeyStore 基于内存的密钥存储，并发安全
+type MemoryKeyStore struct {
+	mu   sync.RWMutex
+	keys []storedKey
+}
+
+// NewMemoryKeyStore 创建内存密钥存储
+func NewMemoryKeyStore(keys ...APIKey) (*MemoryKeyStore, error) {
+	s := &MemoryKeyStore{}
+	if err := s.Replace(keys); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add 添加一个密钥，标签重复时覆盖旧的密钥
+func (s *MemoryKeyStore) Add(key APIKey) error {
+	sk, err := newStoredKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].key.Label == key.Label {
+			s.keys[i] = sk
+			return nil
+		}
+	}
+	s.keys = append(s.keys, sk)
+	return nil
+}
+
+// Remove 按标签删除密钥
+func (s *MemoryKeyStore) Remove(label string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		if s.keys[i].key.Label == label {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// Replace 整体替换所有密钥，任何一个密钥无效时保持原有密钥不变
+func (s *MemoryKeyStore) Replace(keys []APIKey) error {
+	stored := make([]storedKey, 0, len(keys))
+	for _, key := range keys {
+		sk, err := newStoredKey(key)
+		if err != nil {
+			return err
+		}
+		stored = append(stored, sk)
+	}
+
+	s.mu.Lock()
+	s.keys = stored
+	s.mu.Unlock()
+	return nil
+}
+
+// Keys 返回当前所有密钥的副本
+func (s *MemoryKeyStore) Keys() []APIKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, sk := range s.keys {
+		keys = append(keys, sk.key)
+	}
+	return keys
+}
+
+// Verify 校验密钥明文
+// 会与所有密钥做常量时间比较，不会因为提前命中而泄露比较耗时
+func (s *MemoryKeyStore) Verify(ctx context.Context, raw string) (*APIKey, error) {
+	sum := sha256.Sum256([]byte(raw))
+	now := time.Now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var valid *APIKey
+	var lastErr error
+	for i := range s.keys {
+		if subtle.ConstantTimeCompare(s.keys[i].hash[:], sum[:]) != 1 {
+			continue
+		}
+		key := s.keys[i].key
+		switch {
+		case !key.NotBefore.IsZero() && now.Before(key.NotBefore):
+			lastErr = ErrAPIKeyNotYetValid
+		case !key.ExpiresAt.IsZero() && !now.Before(key.ExpiresAt):
+			lastErr = ErrAPIKeyExpired
+		case valid == nil:
+			valid = &key
+		}
+	}
+
+	if valid != nil {
+		return valid, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, ErrAPIKeyNotFound
+}
+
+func newStoredKey(key APIKey) (storedKey, error) {
+	if key.Label == "" {
+		return storedKey{}, fmt.Errorf("%w: empty label", ErrAPIKeyInvalidEntry)
+	}
+	b, err := hex.DecodeString(key.Hash)
+	if err != nil || len(b) != sha256.Size {
+		return storedKey{}, fmt.Errorf("%w: label=%s, hash must be hex encoded sha256", ErrAPIKeyInvalidEntry, key.Label)
+	}
+	sk := storedKey{key: key}
+	copy(sk.hash[:], b)
+	return sk, nil
+}
+
+// FileKeyStore 基于文件的密钥存储
+// 文件内容为 APIKey 的 JSON 数组，按固定间隔检查文件修改时间并重新加载
+// 新文件解析失败时记录日志并继续使用旧的密钥
+type FileKeyStore struct {
+	*MemoryKeyStore
+	reloadMu sync.Mutex
+	path     string
+	modTime  time.Time
+	size     int64
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFileKeyStore 创建基于文件的密钥存储，interval 为检查文件变化的间隔，小于等于0时不自动重新加载
+func NewFileKeyStore(path string, interval time.Duration) (*FileKeyStore, error) {
+	s := &FileKeyStore{
+		MemoryKeyStore: &MemoryKeyStore{},
+		path:           path,
+		stopChan:       make(chan struct{}),
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go s.watchLoop(interval)
+	}
+	return s, nil
+}
+
+// Reload 立即从文件重新加载密钥
+func (s *FileKeyStore) Reload() error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	return s.reload()
+}
+
+func (s *FileKeyStore) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat key file: %v", err)
+	}
+	// 无论解析是否成功都记录文件状态，避免同一个错误文件每次轮询都重复报错
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read key file: %v", err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("failed to parse key file: %v", err)
+	}
+
+	return s.Replace(keys)
+}
+
+// watchLoop 轮询文件变化
+func (s *FileKeyStore) watchLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(s.path)
+			if err != nil {
+				log.Printf("api key store: stat %s failed: %v", s.path, err)
+				continue
+			}
+			s.reloadMu.Lock()
+			if !info.ModTime().Equal(s.modTime) || info.Size() != s.size {
+				if err := s.reload(); err != nil {
+					log.Printf("api key store: reload %s failed, keeping previous keys: %v", s.path, err)
+				}
+			}
+			s.reloadMu.Unlock()
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+// Close 停止文件监听
+func (s *FileKeyStore) Close() error {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+	return nil
+}
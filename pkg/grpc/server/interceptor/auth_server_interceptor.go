@@ -20,7 +20,10 @@ package interceptor
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -42,7 +45,7 @@ func AuthServerInterceptor(token string) grpc.UnaryServerInterceptor {
 			return nil, status.Error(codes.Unauthenticated, "missing token")
 		}
 
-		if tokens[0] != token {
+		if subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(token)) != 1 {
 			return nil, status.Error(codes.Unauthenticated, "invalid token")
 		}
 
@@ -64,10 +67,65 @@ func AuthStreamServerInterceptor(token string) grpc.StreamServerInterceptor {
 			return status.Error(codes.Unauthenticated, "missing token")
 		}
 
-		if tokens[0] != token {
+		if subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(token)) != 1 {
 			return status.Error(codes.Unauthenticated, "invalid token")
 		}
 
 		return handler(srv, stream)
 	}
 }
+
+// APIKeyServerInterceptor 基于密钥存储的认证拦截器
+// 支持多个密钥同时有效，校验通过后将调用方身份写入上下文，可通过 attributes.PrincipalFromContext 读取
+func APIKeyServerInterceptor(store KeyStore) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticateAPIKey(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// APIKeyStreamServerInterceptor 基于密钥存储的流式认证拦截器
+func APIKeyStreamServerInterceptor(store KeyStore) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticateAPIKey(stream.Context(), store)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticateAPIKey 从 metadata 中读取密钥并校验，返回携带调用方身份的上下文
+func authenticateAPIKey(ctx context.Context, store KeyStore) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	tokens := md.Get("authorization")
+	if len(tokens) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "missing token")
+	}
+
+	key, err := store.Verify(ctx, tokens[0])
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrAPIKeyExpired):
+		return nil, status.Error(codes.Unauthenticated, "token expired")
+	case errors.Is(err, ErrAPIKeyNotYetValid):
+		return nil, status.Error(codes.Unauthenticated, "token not yet valid")
+	case errors.Is(err, ErrAPIKeyNotFound):
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	default:
+		return nil, status.Errorf(codes.Internal, "failed to verify token: %v", err)
+	}
+
+	return attributes.ContextWithPrincipal(ctx, &attributes.Principal{
+		Subject:  key.Owner,
+		KeyLabel: key.Label,
+		AuthType: "apikey",
+	}), nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BulkheadPool 舱壁隔离池配置
+type BulkheadPool struct {
+	Name          string        `json:"name"`           // 池名称
+	Methods       []string      `json:"methods"`        // 归属该池的方法，支持通配符，例如 "/report.ReportService/*"
+	MaxConcurrent int           `json:"max_concurrent"` // 池内最大并发数
+	MaxWait       time.Duration `json:"max_wait"`       // 名额用完时最长排队时间，为0时不排队直接拒绝
+}
+
+// BulkheadConfig 舱壁隔离配置
+type BulkheadConfig struct {
+	Pools []BulkheadPool `json:"pools"` // 按顺序匹配，方法归属第一个匹配的池，不属于任何池的方法不受限制
+}
+
+// Bulkhead 舱壁隔离
+// 将方法划分到相互独立的并发池中，某个池被慢请求占满时只影响池内的方法，
+// 例如耗时的报表查询不会占满所有处理能力而拖垮登录
+type Bulkhead struct {
+	pools   []*bulkheadPool
+	methods sync.Map // 方法到池的映射缓存，值为 *bulkheadPool，不属于任何池时为 nil
+}
+
+// bulkheadPool 运行时的隔离池
+type bulkheadPool struct {
+	config   BulkheadPool
+	slots    chan struct{}
+	waiting  atomic.Int64
+	rejected atomic.Int64
+}
+
+// NewBulkhead 创建舱壁隔离
+func NewBulkhead(config *BulkheadConfig) *Bulkhead {
+	b := &Bulkhead{}
+	for _, pc := range config.Pools {
+		if pc.MaxConcurrent <= 0 {
+			pc.MaxConcurrent = 1
+		}
+		b.pools = append(b.pools, &bulkheadPool{
+			config: pc,
+			slots:  make(chan struct{}, pc.MaxConcurrent),
+		})
+	}
+	return b
+}
+
+// UnaryServerInterceptor 一元请求舱壁隔离拦截器
+func (b *Bulkhead) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		pool := b.pool(info.FullMethod)
+		if pool == nil {
+			return handler(ctx, req)
+		}
+		if err := pool.acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer pool.release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 流式请求舱壁隔离拦截器，流在整个生命周期内占用一个名额
+func (b *Bulkhead) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		pool := b.pool(info.FullMethod)
+		if pool == nil {
+			return handler(srv, stream)
+		}
+		if err := pool.acquire(stream.Context()); err != nil {
+			return err
+		}
+		defer pool.release()
+		return handler(srv, stream)
+	}
+}
+
+// Stats 返回每个池的并发数、排队数和拒绝次数
+func (b *Bulkhead) Stats() map[string]interface{} {
+	stats := make(map[string]interface{}, len(b.pools))
+	for _, pool := range b.pools {
+		stats[pool.config.Name] = map[string]interface{}{
+			"max_concurrent": pool.config.MaxConcurrent,
+			"active":         len(pool.slots),
+			"waiting":        pool.waiting.Load(),
+			"rejected":       pool.rejected.Load(),
+		}
+	}
+	return stats
+}
+
+// pool 查找方法所属的池
+func (b *Bulkhead) pool(fullMethod string) *bulkheadPool {
+	if v, ok := b.methods.Load(fullMethod); ok {
+		return v.(*bulkheadPool)
+	}
+
+	var matched *bulkheadPool
+	for _, pool := range b.pools {
+		if attributes.MatchAny(pool.config.Methods, fullMethod) {
+			matched = pool
+			break
+		}
+	}
+	b.methods.Store(fullMethod, matched)
+	return matched
+}
+
+// acquire 获取名额，最多等待 MaxWait
+func (p *bulkheadPool) acquire(ctx context.Context) error {
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if p.config.MaxWait <= 0 {
+		p.rejected.Add(1)
+		return status.Errorf(codes.Unavailable, "bulkhead full: pool=%s", p.config.Name)
+	}
+
+	p.waiting.Add(1)
+	defer p.waiting.Add(-1)
+
+	timer := time.NewTimer(p.config.MaxWait)
+	defer timer.Stop()
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		p.rejected.Add(1)
+		return status.Errorf(codes.Unavailable, "bulkhead full: pool=%s, waited %s", p.config.Name, p.config.MaxWait)
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// release 释放名额
+func (p *bulkheadPool) release() {
+	<-p.slots
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeadlinePolicy 方法的截止时间策略，各项为0表示不限制
+type DeadlinePolicy struct {
+	Methods      []string      `json:"methods"`       // 适用的方法，支持通配符
+	Default      time.Duration `json:"default"`       // 客户端未设置截止时间时使用的超时，为0时使用 Max
+	Max          time.Duration `json:"max"`           // 允许的最大超时，客户端设置的更长超时会被截断
+	MinRemaining time.Duration `json:"min_remaining"` // 剩余时间少于此值的请求直接拒绝，避免做注定超时的无用功
+}
+
+// DeadlineConfig 截止时间策略配置
+type DeadlineConfig struct {
+	Policies []DeadlinePolicy `json:"policies"` // 按顺序匹配，使用第一个匹配的策略
+	Fallback DeadlinePolicy   `json:"fallback"` // 没有匹配的策略时使用，Methods 字段被忽略
+}
+
+// DefaultDeadlineConfig 返回默认配置：未设置截止时间的请求默认30秒，最长5分钟
+func DefaultDeadlineConfig(policies ...DeadlinePolicy) *DeadlineConfig {
+	return &DeadlineConfig{
+		Policies: policies,
+		Fallback: DeadlinePolicy{
+			Default: 30 * time.Second,
+			Max:     5 * time.Minute,
+		},
+	}
+}
+
+// DeadlineEnforcer 服务端截止时间策略
+// 与客户端的 TimeoutClientInterceptor 配合使用：客户端决定愿意等多久，服务端决定最多愿意处理多久
+type DeadlineEnforcer struct {
+	config  *DeadlineConfig
+	methods sync.Map // 方法到策略的映射缓存
+}
+
+// NewDeadlineEnforcer 创建截止时间策略
+func NewDeadlineEnforcer(config *DeadlineConfig) *DeadlineEnforcer {
+	if config == nil {
+		config = DefaultDeadlineConfig()
+	}
+	return &DeadlineEnforcer{config: config}
+}
+
+// UnaryServerInterceptor 一元请求截止时间拦截器
+func (e *DeadlineEnforcer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel, err := e.apply(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 流式请求截止时间拦截器，截止时间作用于整个流
+func (e *DeadlineEnforcer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel, err := e.apply(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		return handler(srv, &wrappedServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// apply 按策略调整上下文的截止时间
+func (e *DeadlineEnforcer) apply(ctx context.Context, fullMethod string) (context.Context, context.CancelFunc, error) {
+	policy := e.policy(fullMethod)
+
+	remaining, ok := RemainingBudget(ctx)
+	if !ok {
+		// 未配置 Default 时使用 Max；Default 超过 Max 时以 Max 为准
+		timeout := policy.Default
+		if policy.Max > 0 && (timeout <= 0 || timeout > policy.Max) {
+			timeout = policy.Max
+		}
+		if timeout > 0 {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			return ctx, cancel, nil
+		}
+		return ctx, func() {}, nil
+	}
+
+	if policy.MinRemaining > 0 && remaining < policy.MinRemaining {
+		return nil, nil, status.Errorf(codes.DeadlineExceeded,
+			"insufficient deadline budget: method=%s, remaining=%s, required=%s", fullMethod, remaining, policy.MinRemaining)
+	}
+	if policy.Max > 0 && remaining > policy.Max {
+		ctx, cancel := context.WithTimeout(ctx, policy.Max)
+		return ctx, cancel, nil
+	}
+	return ctx, func() {}, nil
+}
+
+// policy 查找方法对应的策略
+func (e *DeadlineEnforcer) policy(fullMethod string) *DeadlinePolicy {
+	if v, ok := e.methods.Load(fullMethod); ok {
+		return v.(*DeadlinePolicy)
+	}
+
+	policy := &e.config.Fallback
+	for i := range e.config.Policies {
+		if attributes.MatchAny(e.config.Policies[i].Methods, fullMethod) {
+			policy = &e.config.Policies[i]
+			break
+		}
+	}
+	e.methods.Store(fullMethod, policy)
+	return policy
+}
+
+// RemainingBudget 返回请求剩余的处理时间，上下文没有截止时间时返回 false
+// handler 可以据此决定是否跳过可选的耗时步骤，或为下游调用预留时间
+func RemainingBudget(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"context"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/fault"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// FaultServerInterceptor 故障注入拦截器，按规则或请求携带的 fault.HeaderKey（仅 faultinject 构建）注入延迟和错误
+func FaultServerInterceptor(injector *fault.Injector) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if err := injector.Inject(ctx, info.FullMethod, md); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// FaultStreamServerInterceptor 流式请求故障注入拦截器，故障在建流时注入
+func FaultStreamServerInterceptor(injector *fault.Injector) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if err := injector.Inject(stream.Context(), info.FullMethod, md); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
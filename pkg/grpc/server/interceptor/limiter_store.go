@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limit 令牌桶参数
+type Limit struct {
+	Rate  float64 // 每秒生成的令牌数
+	Burst int     // 桶容量
+}
+
+// LimitResult 一次取令牌的结果
+type LimitResult struct {
+	Allowed    bool          // 是否取得令牌
+	Remaining  int           // 取得令牌后桶中剩余的令牌数
+	RetryAfter time.Duration // 未取得令牌时，建议的重试等待时间
+
+	cancel func() // 归还令牌，存储不支持时为空
+}
+
+// LimiterStore 限流计数存储
+// RateLimiter 通过它读写每个限流键的令牌桶状态，实现可以是进程内的，也可以是多个副本共享的
+type LimiterStore interface {
+	// Allow 尝试从 key 对应的令牌桶中取一个令牌，存储不可用时返回 error
+	Allow(ctx context.Context, key string, limit Limit) (*LimitResult, error)
+}
+
+// MemoryLimiterStore 进程内的限流计数存储
+// 令牌桶保存在有容量上限的 LRU 中，空闲的令牌桶会被淘汰
+type MemoryLimiterStore struct {
+	limiters *limiterCache
+}
+
+// NewMemoryLimiterStore 创建进程内的限流计数存储
+func NewMemoryLimiterStore(maxKeys int, idleTimeout time.Duration) *MemoryLimiterStore {
+	if maxKeys <= 0 {
+		maxKeys = 10000
+	}
+	return &MemoryLimiterStore{
+		limiters: newLimiterCache(maxKeys, idleTimeout),
+	}
+}
+
+// Allow 实现 LimiterStore
+func (s *MemoryLimiterStore) Allow(ctx context.Context, key string, limit Limit) (*LimitResult, error) {
+	limiter := s.limiters.get(key, func() *rate.Limiter {
+		return rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+	})
+
+	now := time.Now()
+	r := limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); !r.OK() || delay > 0 {
+		r.CancelAt(now)
+		return &LimitResult{Allowed: false, RetryAfter: delay}, nil
+	}
+
+	remaining := int(limiter.TokensAt(now))
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &LimitResult{
+		Allowed:   true,
+		Remaining: remaining,
+		cancel: func() {
+			r.CancelAt(now)
+		},
+	}, nil
+}
+
+// limiterCache 限流器的 LRU 缓存
+type limiterCache struct {
+	mu          sync.Mutex
+	maxKeys     int
+	idleTimeout time.Duration
+	ll          *list.List
+	items       map[string]*list.Element
+}
+
+// limiterEntry LRU 中的一项
+type limiterEntry struct {
+	key      string
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+func newLimiterCache(maxKeys int, idleTimeout time.Duration) *limiterCache {
+	return &limiterCache{
+		maxKeys:     maxKeys,
+		idleTimeout: idleTimeout,
+		ll:          list.New(),
+		items:       make(map[string]*list.Element),
+	}
+}
+
+// get 获取限流器，不存在时使用 factory 创建
+func (c *limiterCache) get(key string, factory func() *rate.Limiter) *rate.Limiter {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*limiterEntry)
+		entry.lastUsed = now
+		c.ll.MoveToFront(elem)
+		return entry.limiter
+	}
+
+	// 链表按最近使用时间排序，从尾部开始淘汰空闲的限流器
+	if c.idleTimeout > 0 {
+		for back := c.ll.Back(); back != nil; back = c.ll.Back() {
+			if now.Sub(back.Value.(*limiterEntry).lastUsed) <= c.idleTimeout {
+				break
+			}
+			c.removeElement(back)
+		}
+	}
+	for c.ll.Len() >= c.maxKeys {
+		c.removeElement(c.ll.Back())
+	}
+
+	entry := &limiterEntry{key: key, limiter: factory(), lastUsed: now}
+	c.items[key] = c.ll.PushFront(entry)
+	return entry.limiter
+}
+
+func (c *limiterCache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*limiterEntry).key)
+}
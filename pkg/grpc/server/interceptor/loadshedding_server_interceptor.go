@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// SheddingThreshold 某个优先级的降载阈值，任一阈值被超过时拒绝该优先级的新请求，为0表示不限制
+type SheddingThreshold struct {
+	MaxInFlight   int           // 正在处理和排队的请求总数上限
+	MaxQueueDelay time.Duration // 排队最久的请求已等待的时间上限
+}
+
+// LoadSheddingConfig 按优先级降载配置
+type LoadSheddingConfig struct {
+	// MaxConcurrency 同时执行的请求数，超出的请求排队等待；为0时不排队，只按在途请求数降载
+	MaxConcurrency int
+	// Thresholds 各优先级的降载阈值，低优先级的阈值应当更小，过载时先被拒绝
+	Thresholds map[attributes.Priority]SheddingThreshold
+	// DefaultPriority 请求未携带或携带无法识别的优先级时使用的优先级
+	DefaultPriority attributes.Priority
+}
+
+// DefaultLoadSheddingMaxConcurrency 未指定并发数时默认的同时执行请求数
+const DefaultLoadSheddingMaxConcurrency = 100
+
+// DefaultLoadSheddingConfig 返回默认配置，关键请求不受限制；maxConcurrency 小于等于0时使用 DefaultLoadSheddingMaxConcurrency
+func DefaultLoadSheddingConfig(maxConcurrency int) *LoadSheddingConfig {
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultLoadSheddingMaxConcurrency
+	}
+	return &LoadSheddingConfig{
+		MaxConcurrency: maxConcurrency,
+		Thresholds: map[attributes.Priority]SheddingThreshold{
+			attributes.PrioritySheddable: {MaxInFlight: maxConcurrency, MaxQueueDelay: 20 * time.Millisecond},
+			attributes.PriorityDefault:   {MaxInFlight: maxConcurrency * 2, MaxQueueDelay: 200 * time.Millisecond},
+		},
+		DefaultPriority: attributes.PriorityDefault,
+	}
+}
+
+// LoadShedder 按优先级降载
+// 在途请求数或排队延迟超过某个优先级的阈值时，拒绝该优先级的新请求（Unavailable），
+// 低优先级的阈值更小，因此总是先被拒绝，为关键请求保留处理能力
+type LoadShedder struct {
+	config *LoadSheddingConfig
+	slots  chan struct{} // 执行名额，MaxConcurrency 为0时为空
+
+	mu       sync.Mutex
+	inflight int
+	waiters  *list.List // 排队中的请求的入队时间，按入队顺序排列
+	shed     map[attributes.Priority]int64
+}
+
+// NewLoadShedder 创建降载器
+// config 为空或没有配置任何并发数和阈值时使用 DefaultLoadSheddingConfig 的默认值，避免降载器静默失效
+func NewLoadShedder(config *LoadSheddingConfig) *LoadShedder {
+	if config == nil {
+		config = DefaultLoadSheddingConfig(0)
+	} else if !config.limited() {
+		// 在副本上补全默认值，不修改调用方传入的配置
+		copied := *config
+		defaults := DefaultLoadSheddingConfig(0)
+		copied.MaxConcurrency = defaults.MaxConcurrency
+		copied.Thresholds = defaults.Thresholds
+		config = &copied
+	}
+	s := &LoadShedder{
+		config:  config,
+		waiters: list.New(),
+		shed:    make(map[attributes.Priority]int64),
+	}
+	if config.MaxConcurrency > 0 {
+		s.slots = make(chan struct{}, config.MaxConcurrency)
+	}
+	return s
+}
+
+// limited 是否配置了并发数或任一非零阈值
+func (c *LoadSheddingConfig) limited() bool {
+	if c.MaxConcurrency > 0 {
+		return true
+	}
+	for _, threshold := range c.Thresholds {
+		if threshold.MaxInFlight > 0 || threshold.MaxQueueDelay > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryServerInterceptor 一元请求降载拦截器
+func (s *LoadShedder) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		release, err := s.admit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 流式请求降载拦截器，流在整个生命周期内占用一个名额
+func (s *LoadShedder) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		release, err := s.admit(stream.Context())
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, stream)
+	}
+}
+
+// Stats 返回在途请求数、排队请求数、当前排队延迟和各优先级的拒绝次数
+func (s *LoadShedder) Stats() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shed := make(map[string]int64, len(s.shed))
+	for p, n := range s.shed {
+		shed[p.String()] = n
+	}
+	return map[string]interface{}{
+		"inflight":       s.inflight,
+		"queued":         s.waiters.Len(),
+		"queue_delay_ms": s.queueDelayLocked(time.Now()).Milliseconds(),
+		"shed":           shed,
+	}
+}
+
+// admit 判断是否接收请求，接收时返回释放函数
+func (s *LoadShedder) admit(ctx context.Context) (func(), error) {
+	priority := s.priority(ctx)
+	threshold := s.config.Thresholds[priority]
+	now := time.Now()
+
+	s.mu.Lock()
+	if (threshold.MaxInFlight > 0 && s.inflight >= threshold.MaxInFlight) ||
+		(threshold.MaxQueueDelay > 0 && s.queueDelayLocked(now) > threshold.MaxQueueDelay) {
+		s.shed[priority]++
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.Unavailable, "server overloaded, request shed: priority=%s", priority)
+	}
+	s.inflight++
+	s.mu.Unlock()
+
+	done := func() {
+		s.mu.Lock()
+		s.inflight--
+		s.mu.Unlock()
+	}
+
+	if s.slots == nil {
+		return done, nil
+	}
+
+	// 有空闲名额时直接执行，否则排队
+	select {
+	case s.slots <- struct{}{}:
+		return func() { <-s.slots; done() }, nil
+	default:
+	}
+
+	s.mu.Lock()
+	elem := s.waiters.PushBack(now)
+	s.mu.Unlock()
+
+	select {
+	case s.slots <- struct{}{}:
+		s.mu.Lock()
+		s.waiters.Remove(elem)
+		s.mu.Unlock()
+		return func() { <-s.slots; done() }, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		s.waiters.Remove(elem)
+		s.mu.Unlock()
+		done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// queueDelayLocked 排队最久的请求已等待的时间，没有排队请求时为0，调用方需持有锁
+func (s *LoadShedder) queueDelayLocked(now time.Time) time.Duration {
+	front := s.waiters.Front()
+	if front == nil {
+		return 0
+	}
+	return now.Sub(front.Value.(time.Time))
+}
+
+// priority 读取请求的优先级
+func (s *LoadShedder) priority(ctx context.Context) attributes.Priority {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return s.config.DefaultPriority
+	}
+	values := md.Get(attributes.PriorityMetadataKey)
+	if len(values) == 0 {
+		return s.config.DefaultPriority
+	}
+	if p, ok := attributes.ParsePriority(values[0]); ok {
+		return p
+	}
+	return s.config.DefaultPriority
+}
@@ -19,20 +19,331 @@ package interceptor
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
 
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
 	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // RateLimitServerInterceptor 限流拦截器
 func RateLimitServerInterceptor(limit int) grpc.UnaryServerInterceptor {
 	limiter := rate.NewLimiter(rate.Limit(limit), limit)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if !limiter.Allow() {
-			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		now := time.Now()
+		r := limiter.ReserveN(now, 1)
+		if delay := r.DelayFrom(now); !r.OK() || delay > 0 {
+			r.CancelAt(now)
+			return nil, rateLimitExceededError("global", "rate limit exceeded", retryDelay(delay, limiter.Limit()))
 		}
 		return handler(ctx, req)
 	}
 }
+
+// rateLimitExceededError 构造限流错误
+// 状态中携带 RetryInfo（建议的重试等待时间）和 QuotaFailure（触发限流的配额），客户端可以据此退避
+func rateLimitExceededError(subject, description string, retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, description)
+	detailed, err := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     subject,
+			Description: description,
+		}}},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// retryDelay 计算被拒绝的请求需要等待多久才能获得令牌
+func retryDelay(delay time.Duration, limit rate.Limit) time.Duration {
+	if delay > 0 && delay != rate.InfDuration {
+		return delay
+	}
+	// 无法预约（例如突发容量为0）时按一个令牌的生成时间估算
+	if limit > 0 && limit != rate.Inf {
+		return time.Duration(float64(time.Second) / float64(limit))
+	}
+	return time.Second
+}
+
+// RateLimitDimension 限流维度
+type RateLimitDimension string
+
+const (
+	RateLimitByGlobal    RateLimitDimension = "global"    // 所有请求共享一个限流器
+	RateLimitByMethod    RateLimitDimension = "method"    // 按方法限流
+	RateLimitByPeerIP    RateLimitDimension = "peer_ip"   // 按客户端IP限流
+	RateLimitByPrincipal RateLimitDimension = "principal" // 按认证后的调用方限流
+	RateLimitByMetadata  RateLimitDimension = "metadata"  // 按指定 metadata 的值限流，例如租户ID
+)
+
+// RateLimitRule 限流规则
+// 一个请求需要同时满足所有匹配的规则；取不到维度值的请求（例如未认证时按调用方限流）不受该规则限制
+type RateLimitRule struct {
+	Name        string             `json:"name"`         // 规则名称，用于区分不同规则的限流器
+	Dimension   RateLimitDimension `json:"dimension"`    // 限流维度
+	MetadataKey string             `json:"metadata_key"` // 按 metadata 限流时使用的键
+	Methods     []string           `json:"methods"`      // 生效的方法，支持通配符，为空时对所有方法生效
+	Rate        float64            `json:"rate"`         // 每秒允许的请求数
+	Burst       int                `json:"burst"`        // 突发容量，小于等于0时等于 Rate（至少为1）
+}
+
+// RateLimitConfig 多维度限流配置
+type RateLimitConfig struct {
+	Rules       []RateLimitRule `json:"rules"`        // 限流规则
+	MaxKeys     int             `json:"max_keys"`     // 最多保留的限流器数量，超出时淘汰最久未使用的
+	IdleTimeout time.Duration   `json:"idle_timeout"` // 限流器空闲超过此时间后被淘汰
+
+	// DisableQuotaHeaders 为 true 时不在成功的响应头中返回剩余配额
+	DisableQuotaHeaders bool `json:"disable_quota_headers"`
+
+	// Store 限流计数存储，为空时使用进程内的 MemoryLimiterStore（容量和空闲时间取 MaxKeys、IdleTimeout）
+	// 多副本部署时使用 RedisLimiterStore 等共享存储，使限流对所有副本生效
+	Store LimiterStore `json:"-"`
+	// FailOpen 存储不可用时的处理方式，true 时放行请求，false 时返回 Unavailable
+	FailOpen bool `json:"fail_open"`
+}
+
+// 剩余配额响应头，取所有匹配规则中剩余配额最少的一个
+const (
+	RateLimitLimitHeader     = "x-ratelimit-limit"
+	RateLimitRemainingHeader = "x-ratelimit-remaining"
+)
+
+// quotaState 请求通过限流后的配额状态
+type quotaState struct {
+	limit     int // 突发容量
+	remaining int // 剩余令牌数
+}
+
+// DefaultRateLimitConfig 返回默认配置
+func DefaultRateLimitConfig(rules ...RateLimitRule) *RateLimitConfig {
+	return &RateLimitConfig{
+		Rules:       rules,
+		MaxKeys:     10000,
+		IdleTimeout: 10 * time.Minute,
+	}
+}
+
+// RateLimiter 多维度限流器
+// 每个规则按维度值分别维护令牌桶，令牌桶保存在 LimiterStore 中
+type RateLimiter struct {
+	config       *RateLimitConfig
+	store        LimiterStore
+	lastErrorLog atomic.Int64 // 上次记录存储错误日志的时间，用于限制日志频率
+}
+
+// NewRateLimiter 创建多维度限流器，规则的维度无效时返回错误
+func NewRateLimiter(config *RateLimitConfig) (*RateLimiter, error) {
+	if config == nil {
+		config = DefaultRateLimitConfig()
+	}
+	// 在副本上补全默认值，不修改调用方传入的配置
+	copied := *config
+	copied.Rules = append([]RateLimitRule(nil), config.Rules...)
+	config = &copied
+
+	for i := range config.Rules {
+		if err := validateRateLimitRule(&config.Rules[i]); err != nil {
+			return nil, fmt.Errorf("rate limit rule %s: %v", ruleName(&config.Rules[i], i), err)
+		}
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = 10000
+	}
+	store := config.Store
+	if store == nil {
+		store = NewMemoryLimiterStore(config.MaxKeys, config.IdleTimeout)
+	}
+	return &RateLimiter{
+		config: config,
+		store:  store,
+	}, nil
+}
+
+// validateRateLimitRule 校验规则的维度，避免维度拼写错误时规则被静默跳过
+func validateRateLimitRule(rule *RateLimitRule) error {
+	switch rule.Dimension {
+	case RateLimitByGlobal, "", RateLimitByMethod, RateLimitByPeerIP, RateLimitByPrincipal:
+		return nil
+	case RateLimitByMetadata:
+		if rule.MetadataKey == "" {
+			return fmt.Errorf("dimension %q requires metadata_key", rule.Dimension)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown dimension %q", rule.Dimension)
+	}
+}
+
+// UnaryServerInterceptor 一元请求限流拦截器
+func (l *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		quota, err := l.allow(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if md := l.quotaHeader(quota); md != nil {
+			_ = grpc.SetHeader(ctx, md)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 流式请求限流拦截器，对流的建立进行限流
+func (l *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		quota, err := l.allow(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		if md := l.quotaHeader(quota); md != nil {
+			_ = stream.SetHeader(md)
+		}
+		return handler(srv, stream)
+	}
+}
+
+// allow 检查请求是否满足所有匹配的规则，通过时返回剩余配额最少的规则的配额状态
+// 任意一个规则拒绝时，尽可能归还之前规则已经取得的令牌（取决于存储是否支持）
+func (l *RateLimiter) allow(ctx context.Context, fullMethod string) (*quotaState, error) {
+	var granted []*LimitResult
+	cancelAll := func() {
+		for _, r := range granted {
+			if r.cancel != nil {
+				r.cancel()
+			}
+		}
+	}
+
+	var quota *quotaState
+	for i := range l.config.Rules {
+		rule := &l.config.Rules[i]
+		if len(rule.Methods) > 0 && !attributes.MatchAny(rule.Methods, fullMethod) {
+			continue
+		}
+		value, ok := rateLimitKey(ctx, rule, fullMethod)
+		if !ok {
+			continue
+		}
+
+		name := ruleName(rule, i)
+		limit := Limit{Rate: rule.Rate, Burst: ruleBurst(rule)}
+		result, err := l.store.Allow(ctx, name+"|"+value, limit)
+		if err != nil {
+			l.logStoreError(err)
+			if l.config.FailOpen {
+				continue
+			}
+			cancelAll()
+			return nil, status.Errorf(codes.Unavailable, "rate limit store unavailable: %v", err)
+		}
+
+		if !result.Allowed {
+			cancelAll()
+			return nil, rateLimitExceededError(
+				string(rule.Dimension)+":"+value,
+				"rate limit exceeded: rule="+name,
+				retryDelay(result.RetryAfter, rate.Limit(limit.Rate)),
+			)
+		}
+		granted = append(granted, result)
+
+		if quota == nil || result.Remaining < quota.remaining {
+			quota = &quotaState{limit: limit.Burst, remaining: result.Remaining}
+		}
+	}
+	return quota, nil
+}
+
+// logStoreError 记录存储错误，每10秒最多记录一次，避免存储故障时刷屏
+func (l *RateLimiter) logStoreError(err error) {
+	now := time.Now().UnixNano()
+	last := l.lastErrorLog.Load()
+	if now-last < int64(10*time.Second) || !l.lastErrorLog.CompareAndSwap(last, now) {
+		return
+	}
+	log.Printf("rate limiter: store error (fail_open=%v): %v", l.config.FailOpen, err)
+}
+
+// quotaHeader 构造剩余配额响应头，没有匹配的规则或禁用时返回 nil
+func (l *RateLimiter) quotaHeader(quota *quotaState) metadata.MD {
+	if quota == nil || l.config.DisableQuotaHeaders {
+		return nil
+	}
+	return metadata.Pairs(
+		RateLimitLimitHeader, strconv.Itoa(quota.limit),
+		RateLimitRemainingHeader, strconv.Itoa(quota.remaining),
+	)
+}
+
+// ruleBurst 返回规则的突发容量，未配置时等于每秒请求数，至少为1
+func ruleBurst(rule *RateLimitRule) int {
+	if rule.Burst > 0 {
+		return rule.Burst
+	}
+	if rule.Rate < 1 {
+		return 1
+	}
+	return int(rule.Rate)
+}
+
+// ruleName 返回规则名称，未配置时使用规则序号
+func ruleName(rule *RateLimitRule, index int) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return "rule-" + strconv.Itoa(index)
+}
+
+// rateLimitKey 根据规则维度取出请求对应的限流键
+func rateLimitKey(ctx context.Context, rule *RateLimitRule, fullMethod string) (string, bool) {
+	switch rule.Dimension {
+	case RateLimitByGlobal, "":
+		return "*", true
+	case RateLimitByMethod:
+		return fullMethod, true
+	case RateLimitByPeerIP:
+		p, ok := peer.FromContext(ctx)
+		if !ok || p.Addr == nil {
+			return "", false
+		}
+		addr := p.Addr.String()
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			return host, true
+		}
+		return addr, true
+	case RateLimitByPrincipal:
+		p, ok := attributes.PrincipalFromContext(ctx)
+		if !ok || p.Subject == "" {
+			return "", false
+		}
+		return p.Subject, true
+	case RateLimitByMetadata:
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok || rule.MetadataKey == "" {
+			return "", false
+		}
+		values := md.Get(rule.MetadataKey)
+		if len(values) == 0 || values[0] == "" {
+			return "", false
+		}
+		return values[0], true
+	default:
+		return "", false
+	}
+}
@@ -19,22 +19,117 @@ package interceptor
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"runtime/debug"
 
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// PanicInfo 一次 panic 的现场信息
+type PanicInfo struct {
+	IncidentID string      // 事件ID，同时返回给客户端，用于关联用户反馈与服务端日志
+	FullMethod string      // 发生 panic 的方法
+	Value      interface{} // recover() 得到的值
+	Stack      []byte      // panic 时的调用栈
+}
+
+// PanicHook panic 回调，例如上报告警，在返回错误给客户端之前同步调用
+type PanicHook func(ctx context.Context, info *PanicInfo)
+
+// RecoveryOption 恢复拦截器选项
+type RecoveryOption func(*recoveryOptions)
+
+type recoveryOptions struct {
+	hook   PanicHook
+	domain string
+}
+
+// WithPanicHook 设置 panic 回调
+func WithPanicHook(hook PanicHook) RecoveryOption {
+	return func(o *recoveryOptions) {
+		o.hook = hook
+	}
+}
+
+// WithErrorDomain 设置返回给客户端的 ErrorInfo 中的 Domain，默认为 "taurus-pro-grpc"
+func WithErrorDomain(domain string) RecoveryOption {
+	return func(o *recoveryOptions) {
+		o.domain = domain
+	}
+}
+
 // RecoveryServerInterceptor 恢复拦截器
-func RecoveryServerInterceptor() grpc.UnaryServerInterceptor {
+// 捕获 handler 中的 panic，记录调用栈，返回带事件ID的 Internal 错误，事件ID放在错误详情 ErrorInfo 的 incident_id 中
+func RecoveryServerInterceptor(opts ...RecoveryOption) grpc.UnaryServerInterceptor {
+	o := newRecoveryOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("Panic recovered: %v", r)
-				err = status.Error(codes.Internal, "Internal server error")
+				err = o.recovered(ctx, info.FullMethod, r)
 			}
 		}()
 		return handler(ctx, req)
 	}
 }
+
+// RecoveryStreamServerInterceptor 流式请求恢复拦截器
+func RecoveryStreamServerInterceptor(opts ...RecoveryOption) grpc.StreamServerInterceptor {
+	o := newRecoveryOptions(opts)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = o.recovered(stream.Context(), info.FullMethod, r)
+			}
+		}()
+		return handler(srv, stream)
+	}
+}
+
+func newRecoveryOptions(opts []RecoveryOption) *recoveryOptions {
+	o := &recoveryOptions{domain: "taurus-pro-grpc"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// recovered 记录 panic 并构造返回给客户端的错误
+func (o *recoveryOptions) recovered(ctx context.Context, fullMethod string, r interface{}) error {
+	info := &PanicInfo{
+		IncidentID: uuid.New().String(),
+		FullMethod: fullMethod,
+		Value:      r,
+		Stack:      debug.Stack(),
+	}
+	log.Printf("Panic recovered: incident_id=%s, method=%s, panic=%v\n%s", info.IncidentID, fullMethod, r, info.Stack)
+
+	if o.hook != nil {
+		o.callHook(ctx, info)
+	}
+
+	st := status.New(codes.Internal, fmt.Sprintf("Internal server error (incident_id=%s)", info.IncidentID))
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   "PANIC",
+		Domain:   o.domain,
+		Metadata: map[string]string{"incident_id": info.IncidentID},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// callHook 调用 panic 回调，回调自身的 panic 不能影响错误返回
+func (o *recoveryOptions) callHook(ctx context.Context, info *PanicInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Panic hook panicked: incident_id=%s, panic=%v", info.IncidentID, r)
+		}
+	}()
+	o.hook(ctx, info)
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gcraScript 使用 GCRA（通用信元速率算法）实现的令牌桶，在 Redis 中原子执行
+// 每个限流键只保存一个理论到达时间（TAT），时间取 Redis 服务器时间，避免各副本时钟不一致
+// KEYS[1]: 限流键  ARGV[1]: 每秒令牌数  ARGV[2]: 桶容量
+// 返回 {是否允许, 剩余令牌数, 重试等待微秒数}
+const gcraScript = `
+pcall(redis.replicate_commands)
+local key = KEYS[1]
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+local emission = 1000000 / rate
+local tolerance = emission * burst
+local tat = tonumber(redis.call('GET', key))
+if not tat or tat < now then
+  tat = now
+end
+local new_tat = tat + emission
+local allow_at = new_tat - tolerance
+if now < allow_at then
+  return {0, 0, math.ceil(allow_at - now)}
+end
+redis.call('SET', key, string.format('%.0f', new_tat), 'PX', math.ceil((new_tat - now) / 1000) + 1)
+return {1, math.floor((now - allow_at) / emission), 0}
+`
+
+// RedisLimiterStoreConfig Redis 限流存储配置
+type RedisLimiterStoreConfig struct {
+	Addr        string        // Redis 地址，例如 127.0.0.1:6379
+	Password    string        // 密码，为空时不认证
+	DB          int           // 数据库编号
+	KeyPrefix   string        // 限流键前缀
+	PoolSize    int           // 最大空闲连接数
+	DialTimeout time.Duration // 建立连接超时时间
+	IOTimeout   time.Duration // 单次命令读写超时时间
+}
+
+// DefaultRedisLimiterStoreConfig 返回默认配置
+func DefaultRedisLimiterStoreConfig(addr string) *RedisLimiterStoreConfig {
+	return &RedisLimiterStoreConfig{
+		Addr:        addr,
+		KeyPrefix:   "taurus:ratelimit:",
+		PoolSize:    10,
+		DialTimeout: time.Second,
+		IOTimeout:   500 * time.Millisecond,
+	}
+}
+
+// RedisLimiterStore 基于 Redis 协议的共享限流计数存储
+// 只依赖 RESP 协议和 Lua 脚本，兼容 Redis 以及支持 EVAL 的兼容实现
+type RedisLimiterStore struct {
+	config    *RedisLimiterStoreConfig
+	scriptSHA string
+
+	mu     sync.Mutex
+	idle   []*redisConn
+	closed bool
+}
+
+// NewRedisLimiterStore 创建 Redis 限流存储，连接按需建立
+func NewRedisLimiterStore(config *RedisLimiterStoreConfig) *RedisLimiterStore {
+	if config.PoolSize <= 0 {
+		config.PoolSize = 10
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = time.Second
+	}
+	if config.IOTimeout <= 0 {
+		config.IOTimeout = 500 * time.Millisecond
+	}
+	sum := sha1.Sum([]byte(gcraScript))
+	return &RedisLimiterStore{
+		config:    config,
+		scriptSHA: hex.EncodeToString(sum[:]),
+	}
+}
+
+// Allow 实现 LimiterStore
+func (s *RedisLimiterStore) Allow(ctx context.Context, key string, limit Limit) (*LimitResult, error) {
+	if limit.Rate <= 0 {
+		return &LimitResult{Allowed: false, RetryAfter: time.Second}, nil
+	}
+
+	conn, err := s.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rateArg := strconv.FormatFloat(limit.Rate, 'f', -1, 64)
+	burstArg := strconv.Itoa(limit.Burst)
+	fullKey := s.config.KeyPrefix + key
+
+	reply, err := conn.do(ctx, s.config.IOTimeout, "EVALSHA", s.scriptSHA, "1", fullKey, rateArg, burstArg)
+	var redisErr redisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		reply, err = conn.do(ctx, s.config.IOTimeout, "EVAL", gcraScript, "1", fullKey, rateArg, burstArg)
+	}
+	if err != nil {
+		// 协议层错误时连接状态不可知，直接丢弃；Redis 返回的业务错误不影响连接
+		if errors.As(err, &redisErr) {
+			s.putConn(conn)
+		} else {
+			conn.close()
+		}
+		return nil, err
+	}
+	s.putConn(conn)
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return nil, fmt.Errorf("redis limiter: unexpected script reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryAfter, _ := values[2].(int64)
+
+	return &LimitResult{
+		Allowed:    allowed == 1,
+		Remaining:  int(remaining),
+		RetryAfter: time.Duration(retryAfter) * time.Microsecond,
+	}, nil
+}
+
+// Close 关闭所有空闲连接
+func (s *RedisLimiterStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	var lastErr error
+	for _, conn := range s.idle {
+		if err := conn.close(); err != nil {
+			lastErr = err
+		}
+	}
+	s.idle = nil
+	return lastErr
+}
+
+// getConn 从空闲连接中取一个，没有时新建连接
+func (s *RedisLimiterStore) getConn(ctx context.Context) (*redisConn, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, errors.New("redis limiter: store closed")
+	}
+	if n := len(s.idle); n > 0 {
+		conn := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return conn, nil
+	}
+	s.mu.Unlock()
+
+	dialer := net.Dialer{Timeout: s.config.DialTimeout}
+	nc, err := dialer.DialContext(ctx, "tcp", s.config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis limiter: dial %s failed: %v", s.config.Addr, err)
+	}
+	conn := &redisConn{conn: nc, reader: bufio.NewReader(nc)}
+
+	if s.config.Password != "" {
+		if _, err := conn.do(ctx, s.config.IOTimeout, "AUTH", s.config.Password); err != nil {
+			conn.close()
+			return nil, fmt.Errorf("redis limiter: auth failed: %v", err)
+		}
+	}
+	if s.config.DB != 0 {
+		if _, err := conn.do(ctx, s.config.IOTimeout, "SELECT", strconv.Itoa(s.config.DB)); err != nil {
+			conn.close()
+			return nil, fmt.Errorf("redis limiter: select db failed: %v", err)
+		}
+	}
+	return conn, nil
+}
+
+// putConn 归还连接，超出空闲连接上限时关闭
+func (s *RedisLimiterStore) putConn(conn *redisConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || len(s.idle) >= s.config.PoolSize {
+		conn.close()
+		return
+	}
+	s.idle = append(s.idle, conn)
+}
+
+// redisError Redis 返回的错误回复
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn 一个 RESP 协议连接
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// do 发送命令并读取回复
+func (c *redisConn) do(ctx context.Context, timeout time.Duration, args ...string) (interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		b.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n" + arg + "\r\n")
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply 读取一个 RESP 回复
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	payload := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.reader, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			if values[i], err = c.readReply(); err != nil {
+				// 数组剩余的元素还没有读取，连接已不可复用，
+				// 不能以 redisError 返回，否则调用方会把连接放回连接池
+				return nil, fmt.Errorf("redis: array element %d: %v", i, err)
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+	}
+}
+
+func (c *redisConn) close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,374 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package interceptor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// respStub 进程内的 RESP 协议桩，按 burst 个令牌一个固定窗口模拟限流脚本
+type respStub struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	commands []string          // 收到的命令名
+	conns    int               // 接受的连接数
+	counts   map[string]int    // 每个键已经取得的令牌数
+	loaded   bool              // 脚本是否已经通过 EVAL 加载
+	replies  map[string]string // 按命令名覆盖的原始回复
+}
+
+func newRESPStub(t *testing.T) *respStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &respStub{
+		listener: ln,
+		counts:   make(map[string]int),
+		replies:  make(map[string]string),
+	}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *respStub) addr() string {
+	return s.listener.Addr().String()
+}
+
+// reply 覆盖命令的回复，raw 为完整的 RESP 回复
+func (s *respStub) reply(command, raw string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if raw == "" {
+		delete(s.replies, command)
+		return
+	}
+	s.replies[command] = raw
+}
+
+// loadScript 模拟脚本已经加载到 Redis 中
+func (s *respStub) loadScript() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded = true
+}
+
+func (s *respStub) stats() ([]string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...), s.conns
+}
+
+func (s *respStub) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns++
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *respStub) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.exec(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *respStub) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	command := strings.ToUpper(args[0])
+	s.commands = append(s.commands, command)
+	if raw, ok := s.replies[command]; ok {
+		return raw
+	}
+
+	switch command {
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "EVALSHA":
+		if !s.loaded {
+			return "-NOSCRIPT No matching script. Please use EVAL.\r\n"
+		}
+	case "EVAL":
+		s.loaded = true
+	default:
+		return "-ERR unknown command '" + args[0] + "'\r\n"
+	}
+
+	// EVAL/EVALSHA script numkeys key rate burst
+	key := args[3]
+	burst, _ := strconv.Atoi(args[5])
+	if s.counts[key] >= burst {
+		return "*3\r\n:0\r\n:0\r\n:250000\r\n"
+	}
+	s.counts[key]++
+	return fmt.Sprintf("*3\r\n:1\r\n:%d\r\n:0\r\n", burst-s.counts[key])
+}
+
+// readCommand 读取一个 RESP 数组形式的命令
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command line %q", line)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestRedisLimiterStoreAllowDeny(t *testing.T) {
+	stub := newRESPStub(t)
+	stub.loadScript()
+
+	config := DefaultRedisLimiterStoreConfig(stub.addr())
+	config.Password = "secret"
+	config.DB = 2
+	store := NewRedisLimiterStore(config)
+	defer store.Close()
+
+	limit := Limit{Rate: 4, Burst: 2}
+	for i, want := range []int{1, 0} {
+		result, err := store.Allow(context.Background(), "user-1", limit)
+		if err != nil {
+			t.Fatalf("request %d: Allow() error: %v", i, err)
+		}
+		if !result.Allowed || result.Remaining != want {
+			t.Fatalf("request %d: got allowed=%v remaining=%d, want allowed remaining=%d", i, result.Allowed, result.Remaining, want)
+		}
+	}
+
+	result, err := store.Allow(context.Background(), "user-1", limit)
+	if err != nil {
+		t.Fatalf("Allow() error: %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("expected request over burst to be denied")
+	}
+	if result.RetryAfter != 250*time.Millisecond {
+		t.Errorf("expected retry after 250ms, got %s", result.RetryAfter)
+	}
+
+	// 不同的键使用独立的令牌桶
+	if result, err := store.Allow(context.Background(), "user-2", limit); err != nil || !result.Allowed {
+		t.Fatalf("expected user-2 to be allowed, got %+v, %v", result, err)
+	}
+
+	commands, conns := stub.stats()
+	if conns != 1 {
+		t.Errorf("expected connection to be reused, got %d connections", conns)
+	}
+	if want := []string{"AUTH", "SELECT", "EVALSHA", "EVALSHA", "EVALSHA", "EVALSHA"}; strings.Join(commands, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected commands %v, want %v", commands, want)
+	}
+	stub.mu.Lock()
+	_, prefixed := stub.counts["taurus:ratelimit:user-1"]
+	stub.mu.Unlock()
+	if !prefixed {
+		t.Error("expected key to carry the configured prefix")
+	}
+}
+
+func TestRedisLimiterStoreNoScriptFallback(t *testing.T) {
+	stub := newRESPStub(t)
+	store := NewRedisLimiterStore(DefaultRedisLimiterStoreConfig(stub.addr()))
+	defer store.Close()
+
+	limit := Limit{Rate: 10, Burst: 10}
+	for i := 0; i < 2; i++ {
+		result, err := store.Allow(context.Background(), "k", limit)
+		if err != nil {
+			t.Fatalf("request %d: Allow() error: %v", i, err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d: expected allowed", i)
+		}
+	}
+
+	// 第一次 EVALSHA 返回 NOSCRIPT 后改用 EVAL 加载脚本，之后直接使用 EVALSHA
+	commands, conns := stub.stats()
+	if want := []string{"EVALSHA", "EVAL", "EVALSHA"}; strings.Join(commands, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected commands %v, want %v", commands, want)
+	}
+	if conns != 1 {
+		t.Errorf("expected NOSCRIPT to keep the connection, got %d connections", conns)
+	}
+}
+
+func TestRedisLimiterStoreErrors(t *testing.T) {
+	stub := newRESPStub(t)
+	stub.loadScript()
+	store := NewRedisLimiterStore(DefaultRedisLimiterStoreConfig(stub.addr()))
+	defer store.Close()
+	limit := Limit{Rate: 10, Burst: 10}
+
+	// Redis 返回错误回复时连接仍然可用
+	stub.reply("EVALSHA", "-ERR max memory reached\r\n")
+	_, err := store.Allow(context.Background(), "k", limit)
+	var redisErr redisError
+	if !errors.As(err, &redisErr) {
+		t.Fatalf("expected redis error reply, got %v", err)
+	}
+	if _, conns := stub.stats(); conns != 1 {
+		t.Fatalf("expected 1 connection, got %d", conns)
+	}
+
+	// 数组中间出现错误元素时剩余元素未读取，连接必须丢弃
+	stub.reply("EVALSHA", "*3\r\n-ERR boom\r\n:0\r\n:0\r\n")
+	if _, err := store.Allow(context.Background(), "k", limit); err == nil {
+		t.Fatal("expected error for error element in array reply")
+	}
+
+	stub.reply("EVALSHA", "")
+	result, err := store.Allow(context.Background(), "k", limit)
+	if err != nil {
+		t.Fatalf("Allow() after error element: %v", err)
+	}
+	if !result.Allowed || result.Remaining != 9 {
+		t.Fatalf("expected clean reply on a new connection, got %+v", result)
+	}
+	if _, conns := stub.stats(); conns != 2 {
+		t.Errorf("expected half-read connection to be closed and redialed, got %d connections", conns)
+	}
+
+	// 无法连接时返回错误
+	stub.listener.Close()
+	store.Close()
+	down := NewRedisLimiterStore(DefaultRedisLimiterStoreConfig(stub.addr()))
+	if _, err := down.Allow(context.Background(), "k", limit); err == nil {
+		t.Fatal("expected dial error when redis is down")
+	}
+}
+
+func TestRateLimiterRedisStoreFailOpen(t *testing.T) {
+	stub := newRESPStub(t)
+	stub.loadScript()
+	stub.reply("EVALSHA", "-ERR LOADING Redis is loading the dataset in memory\r\n")
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/user.UserService/Get"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	for _, failOpen := range []bool{true, false} {
+		t.Run(fmt.Sprintf("fail_open=%v", failOpen), func(t *testing.T) {
+			store := NewRedisLimiterStore(DefaultRedisLimiterStoreConfig(stub.addr()))
+			defer store.Close()
+
+			config := DefaultRateLimitConfig(RateLimitRule{Name: "global", Dimension: RateLimitByGlobal, Rate: 10})
+			config.Store = store
+			config.FailOpen = failOpen
+			limiter, err := NewRateLimiter(config)
+			if err != nil {
+				t.Fatalf("NewRateLimiter() error: %v", err)
+			}
+
+			resp, err := limiter.UnaryServerInterceptor()(context.Background(), nil, info, handler)
+			if failOpen {
+				if err != nil || resp != "ok" {
+					t.Fatalf("expected request to pass when failing open, got %v, %v", resp, err)
+				}
+				return
+			}
+			if status.Code(err) != codes.Unavailable {
+				t.Fatalf("expected Unavailable when failing closed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewRateLimiterValidatesRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    RateLimitRule
+		wantErr bool
+	}{
+		{name: "global", rule: RateLimitRule{Dimension: RateLimitByGlobal, Rate: 1}},
+		{name: "default dimension", rule: RateLimitRule{Rate: 1}},
+		{name: "metadata", rule: RateLimitRule{Dimension: RateLimitByMetadata, MetadataKey: "x-tenant-id", Rate: 1}},
+		{name: "metadata without key", rule: RateLimitRule{Dimension: RateLimitByMetadata, Rate: 1}, wantErr: true},
+		{name: "unknown dimension", rule: RateLimitRule{Dimension: "peer-ip", Rate: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultRateLimitConfig(tt.rule)
+			config.MaxKeys = 0
+			_, err := NewRateLimiter(config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRateLimiter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if config.MaxKeys != 0 {
+				t.Error("NewRateLimiter should not modify the caller's config")
+			}
+		})
+	}
+}
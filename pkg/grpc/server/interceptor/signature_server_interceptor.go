@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"context"
+	"crypto/hmac"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-grpc/pkg/grpc/attributes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// SignatureConfig 请求签名校验配置
+type SignatureConfig struct {
+	Secret       []byte        // 共享密钥
+	MaxClockSkew time.Duration // 允许的时间戳偏差，超出窗口的请求会被拒绝
+	NonceTTL     time.Duration // 随机数缓存时间，必须不小于时间窗口的两倍，否则窗口内的重放无法识别
+}
+
+// DefaultSignatureConfig 返回默认配置
+func DefaultSignatureConfig(secret []byte) *SignatureConfig {
+	return &SignatureConfig{
+		Secret:       secret,
+		MaxClockSkew: 5 * time.Minute,
+		NonceTTL:     10 * time.Minute,
+	}
+}
+
+// SignatureVerifier 校验客户端 SigningClientInterceptor 生成的签名
+// 一元和流式拦截器共用同一个随机数缓存
+type SignatureVerifier struct {
+	config *SignatureConfig
+	nonces *nonceCache
+}
+
+// NewSignatureVerifier 创建签名校验器
+func NewSignatureVerifier(config *SignatureConfig) *SignatureVerifier {
+	// 在副本上补全默认值，不修改调用方传入的配置
+	copied := *config
+	config = &copied
+	if config.MaxClockSkew <= 0 {
+		config.MaxClockSkew = 5 * time.Minute
+	}
+	if config.NonceTTL < 2*config.MaxClockSkew {
+		config.NonceTTL = 2 * config.MaxClockSkew
+	}
+	return &SignatureVerifier{
+		config: config,
+		nonces: newNonceCache(config.NonceTTL),
+	}
+}
+
+// UnaryServerInterceptor 一元请求签名校验拦截器
+func (v *SignatureVerifier) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		payload, err := attributes.SignaturePayload(req)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err := v.verify(ctx, info.FullMethod, req, payload); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor 流式请求签名校验拦截器，只校验建立流时的握手签名
+func (v *SignatureVerifier) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := v.verify(stream.Context(), info.FullMethod, nil, nil); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// verify 校验签名、时间窗口和随机数，req 为解码后的请求，流式握手时为 nil
+func (v *SignatureVerifier) verify(ctx context.Context, method string, req interface{}, payload []byte) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	signature := firstMetadataValue(md, attributes.SignatureMetadataKey)
+	timestamp := firstMetadataValue(md, attributes.SignatureTimestampMetadataKey)
+	nonce := firstMetadataValue(md, attributes.SignatureNonceMetadataKey)
+	if signature == "" || timestamp == "" || nonce == "" {
+		return status.Error(codes.Unauthenticated, "missing signature")
+	}
+
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, "invalid signature timestamp")
+	}
+	skew := time.Since(time.UnixMilli(ms))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > v.config.MaxClockSkew {
+		return status.Error(codes.Unauthenticated, "signature timestamp out of window")
+	}
+
+	expected := attributes.ComputeSignature(v.config.Secret, method, timestamp, nonce, payload)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		// 签名不一致可能是因为服务端不认识客户端新增的字段，重新序列化后的字节与客户端签名的不同
+		if m, ok := req.(proto.Message); ok && attributes.HasUnknownFields(m) {
+			return status.Error(codes.FailedPrecondition, attributes.ErrSignatureUnknownFields.Error())
+		}
+		return status.Error(codes.Unauthenticated, "invalid signature")
+	}
+
+	// 签名通过后才记录随机数，避免伪造请求占满缓存
+	if !v.nonces.add(nonce) {
+		return status.Error(codes.Unauthenticated, "replayed request")
+	}
+	return nil
+}
+
+// firstMetadataValue 返回 metadata 中指定键的第一个值
+func firstMetadataValue(md metadata.MD, key string) string {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// nonceCache 带过期时间的随机数缓存
+type nonceCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]time.Time
+	lastSweep time.Time
+}
+
+func newNonceCache(ttl time.Duration) *nonceCache {
+	return &nonceCache{
+		ttl:       ttl,
+		entries:   make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// add 记录随机数，已存在且未过期时返回 false
+func (c *nonceCache) add(nonce string) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// 按 TTL 的一半为周期清理过期随机数
+	if now.Sub(c.lastSweep) > c.ttl/2 {
+		for k, expireAt := range c.entries {
+			if now.After(expireAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	if expireAt, ok := c.entries[nonce]; ok && now.Before(expireAt) {
+		return false
+	}
+	c.entries[nonce] = now.Add(c.ttl)
+	return true
+}
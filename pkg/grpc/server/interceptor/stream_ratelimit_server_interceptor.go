@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+package interceptor
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// StreamRateLimitConfig 流式请求限流配置
+type StreamRateLimitConfig struct {
+	// StreamLimiter 限制新建流的速率，按规则中的维度分别计数，为空时不限制建流
+	StreamLimiter *RateLimiter
+
+	// MessageRate 每个流每秒允许收发的消息数（收发共用），小于等于0时不限制
+	MessageRate float64
+	// MessageBurst 每个流的消息突发容量，小于等于0时等于 MessageRate（至少为1）
+	MessageBurst int
+	// Block 超出消息速率时的处理方式
+	// true: 阻塞等待令牌，接收端不读取数据，由 HTTP/2 流控向客户端施加背压
+	// false: 立即返回 ResourceExhausted 并结束流
+	Block bool
+}
+
+// StreamRateLimitServerInterceptor 创建流式限流拦截器
+// 同时限制建流速率和每个流内的消息速率，适用于频繁收发消息的双向流客户端；config 为空时不做任何限制
+func StreamRateLimitServerInterceptor(config *StreamRateLimitConfig) grpc.StreamServerInterceptor {
+	if config == nil {
+		config = &StreamRateLimitConfig{}
+	}
+	burst := config.MessageBurst
+	if burst <= 0 {
+		burst = ruleBurst(&RateLimitRule{Rate: config.MessageRate})
+	}
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if config.StreamLimiter != nil {
+			quota, err := config.StreamLimiter.allow(stream.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			if md := config.StreamLimiter.quotaHeader(quota); md != nil {
+				_ = stream.SetHeader(md)
+			}
+		}
+
+		if config.MessageRate <= 0 {
+			return handler(srv, stream)
+		}
+
+		return handler(srv, &rateLimitedServerStream{
+			ServerStream: stream,
+			limiter:      rate.NewLimiter(rate.Limit(config.MessageRate), burst),
+			block:        config.Block,
+		})
+	}
+}
+
+// rateLimitedServerStream 包装 grpc.ServerStream，限制流内的消息速率
+type rateLimitedServerStream struct {
+	grpc.ServerStream
+	limiter *rate.Limiter
+	block   bool
+}
+
+// RecvMsg 接收到消息后再获取令牌，流结束（io.EOF）和接收失败不消耗令牌
+// 阻塞模式下令牌不足时消息暂不交给处理函数，处理函数不会继续读取，仍然由 HTTP/2 流控施加背压
+func (s *rateLimitedServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.wait()
+}
+
+// SendMsg 获取令牌后再发送消息
+func (s *rateLimitedServerStream) SendMsg(m interface{}) error {
+	if err := s.wait(); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// wait 获取一个消息令牌
+func (s *rateLimitedServerStream) wait() error {
+	if !s.block {
+		now := time.Now()
+		r := s.limiter.ReserveN(now, 1)
+		if delay := r.DelayFrom(now); !r.OK() || delay > 0 {
+			r.CancelAt(now)
+			return rateLimitExceededError("stream", "stream message rate limit exceeded", retryDelay(delay, s.limiter.Limit()))
+		}
+		return nil
+	}
+
+	ctx := s.ServerStream.Context()
+	if err := s.limiter.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		// 等待时间超过了流的截止时间
+		return rateLimitExceededError("stream", "stream message rate limit exceeded", retryDelay(0, s.limiter.Limit()))
+	}
+	return nil
+}
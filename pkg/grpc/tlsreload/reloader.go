@@ -0,0 +1,289 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2025-06-13
+
+package tlsreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// CertReloader 从文件加载证书，并在文件变化时自动重新加载
+// 通过 GetCertificate / GetClientCertificate / GetConfigForClient 在每次握手时取最新的证书，
+// 证书轮换（例如 cert-manager 更新 Secret）后无需重启服务。
+// 新证书无效时记录日志并继续使用旧证书。
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string // 可选，服务端用于校验客户端证书，客户端用于校验服务端证书
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	caPool *x509.CertPool
+	stats  map[string]fileStat
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+// fileStat 用于判断文件是否变化
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewCertReloader 创建证书加载器，interval 为轮询文件变化的间隔，小于等于0时不自动重新加载
+func NewCertReloader(certFile, keyFile, caFile string, interval time.Duration) (*CertReloader, error) {
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		stats:    make(map[string]fileStat),
+		stopChan: make(chan struct{}),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go r.watchLoop(interval)
+	}
+	return r, nil
+}
+
+// Reload 立即重新加载证书，加载失败时保留旧证书并返回错误
+func (r *CertReloader) Reload() error {
+	stats, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+
+	var cert *tls.Certificate
+	if r.certFile != "" || r.keyFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load key pair: %v", err)
+		}
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate: %v", err)
+		}
+		if time.Now().After(leaf.NotAfter) {
+			return fmt.Errorf("certificate %s expired at %s", r.certFile, leaf.NotAfter.Format(time.RFC3339))
+		}
+		pair.Leaf = leaf
+		cert = &pair
+	}
+
+	var caPool *x509.CertPool
+	if r.caFile != "" {
+		caPEM, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+		caPool = x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caPEM) {
+			return fmt.Errorf("failed to append CA certificate")
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.caPool = caPool
+	r.stats = stats
+	r.mu.Unlock()
+	return nil
+}
+
+// Certificate 返回当前证书
+func (r *CertReloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// CAPool 返回当前 CA 证书池，未配置 CA 文件时返回 nil
+func (r *CertReloader) CAPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.caPool
+}
+
+// ServerTLSConfig 构建服务端 TLS 配置
+// 配置了 CA 文件时按 clientAuth 校验客户端证书，CA 证书同样支持热更新
+func (r *CertReloader) ServerTLSConfig(clientAuth tls.ClientAuthType) *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ClientAuth: clientAuth,
+		NextProtos: []string{"h2"},
+	}
+
+	config := base.Clone()
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cert := r.Certificate()
+		if cert == nil {
+			return nil, errors.New("no server certificate loaded")
+		}
+		c := base.Clone()
+		c.Certificates = []tls.Certificate{*cert}
+		c.ClientCAs = r.CAPool()
+		return c, nil
+	}
+	return config
+}
+
+// ClientTLSConfig 构建客户端 TLS 配置，serverName 为空时使用连接地址中的主机名
+// 配置了证书文件时提供客户端证书（双向认证）；配置了 CA 文件时使用热更新的 CA 校验服务端证书，否则使用系统根证书
+// 使用 CA 文件且通过 IP 地址连接时必须显式传入 serverName（主机名或 IP），否则握手失败
+func (r *CertReloader) ClientTLSConfig(serverName string) *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if r.certFile != "" {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert := r.Certificate()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		}
+	}
+
+	if r.caFile != "" {
+		// RootCAs 在握手前就固定了，为了支持 CA 轮换，跳过内置校验改为在 VerifyConnection 中使用最新的 CA 校验
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			// 通过 IP 连接时 SNI 中不携带地址，cs.ServerName 为空，不能据此跳过主机名校验
+			name := serverName
+			if name == "" {
+				name = cs.ServerName
+			}
+			if name == "" {
+				return errors.New("no server name to verify the server certificate against, set serverName explicitly")
+			}
+			// DNSName 为 IP 地址时按证书的 IP SAN 校验
+			opts := x509.VerifyOptions{
+				Roots:         r.CAPool(),
+				DNSName:       name,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		}
+	}
+	return config
+}
+
+// Close 停止文件监听
+func (r *CertReloader) Close() error {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+	return nil
+}
+
+// statFiles 获取所有证书文件的状态
+func (r *CertReloader) statFiles() (map[string]fileStat, error) {
+	stats := make(map[string]fileStat)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %v", file, err)
+		}
+		stats[file] = fileStat{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stats, nil
+}
+
+// changed 文件状态是否与上次加载时不同
+func (r *CertReloader) changed(stats map[string]fileStat) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(stats) != len(r.stats) {
+		return true
+	}
+	for file, st := range stats {
+		old, ok := r.stats[file]
+		if !ok || !old.modTime.Equal(st.modTime) || old.size != st.size {
+			return true
+		}
+	}
+	return false
+}
+
+// watchLoop 轮询证书文件变化
+func (r *CertReloader) watchLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 记录最近一次失败的文件状态，避免同一组错误文件每次轮询都重复报错
+	var failed map[string]fileStat
+
+	for {
+		select {
+		case <-ticker.C:
+			stats, err := r.statFiles()
+			if err != nil {
+				log.Printf("tls reloader: %v", err)
+				continue
+			}
+			if !r.changed(stats) || sameFileStats(stats, failed) {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				log.Printf("tls reloader: reload failed, keeping previous certificate: %v", err)
+				failed = stats
+				continue
+			}
+			failed = nil
+			log.Printf("tls reloader: certificate reloaded from %s", r.certFile)
+		case <-r.stopChan:
+			return
+		}
+	}
+}
+
+func sameFileStats(a, b map[string]fileStat) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for file, st := range a {
+		other, ok := b[file]
+		if !ok || !other.modTime.Equal(st.modTime) || other.size != st.size {
+			return false
+		}
+	}
+	return true
+}